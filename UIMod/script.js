@@ -48,6 +48,107 @@ function stopServer() {
         .then(data => typeTextWithCallback(document.getElementById('status'), data, 20));
 }
 
+// wakeServer starts the server again after it was stopped for having no players, viewers may do this too
+function wakeServer() {
+    fetch('/api/wake', { method: 'POST' })
+        .then(response => response.json())
+        .then(data => {
+            typeTextWithCallback(document.getElementById('status'), data.error || 'Server is waking up...', 20);
+            fetchStatus();
+        });
+}
+
+// restartWithCountdown warns the players in game and restarts the server after the longest warning
+function restartWithCountdown() {
+    fetch('/api/restart/countdown', { method: 'POST' })
+        .then(response => response.json())
+        .then(data => {
+            typeTextWithCallback(document.getElementById('status'), data.message || data.error, 20);
+            fetchStatus();
+        });
+}
+
+function cancelRestart() {
+    fetch('/api/restart/countdown', { method: 'DELETE' })
+        .then(response => response.json())
+        .then(data => {
+            typeTextWithCallback(document.getElementById('status'), data.message || data.error, 20);
+            fetchStatus();
+        });
+}
+
+function showCountdown(countdown) {
+    const element = document.getElementById('countdown');
+    if (!countdown) {
+        element.style.display = 'none';
+        return;
+    }
+    const seconds = Math.max(0, Math.round((new Date(countdown.restartAt) - new Date()) / 1000));
+    let text = 'Restart in ' + Math.floor(seconds / 60) + 'm ' + (seconds % 60) + 's (' + countdown.reason + ', requested by ' + countdown.requestedBy + ')';
+    if (countdown.final) {
+        text += ' - final warning sent';
+    }
+    document.getElementById('countdownText').textContent = text;
+    document.getElementById('cancelRestart').disabled = countdown.final;
+    element.style.display = '';
+}
+
+function sendConsoleCommand(event) {
+    event.preventDefault();
+    const input = document.getElementById('consoleInput');
+    const command = input.value.trim();
+    if (!command) {
+        return;
+    }
+    fetch('/api/console', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ command: command, source: 'web' })
+    })
+        .then(response => response.text())
+        .then(data => {
+            typeTextWithCallback(document.getElementById('status'), data, 20);
+            input.value = '';
+        });
+}
+
+function fetchStatus() {
+    fetch('/api/status')
+        .then(response => response.json())
+        .then(status => {
+            let text = 'Server: ' + status.state;
+            if (status.pid) {
+                text += ' | PID ' + status.pid + ' | Uptime ' + formatDuration(status.uptimeSeconds);
+            }
+            text += ' | World ' + status.world + ' | Players ' + status.playerCount;
+            if (status.restartPending) {
+                text += ' | Restart pending';
+            }
+            if (status.idle) {
+                if (status.idle.sleeping) {
+                    text += ' | Asleep, no players for ' + status.idle.stopAfterMinutes + ' minutes';
+                } else if (status.idle.stopAt) {
+                    const seconds = Math.max(0, Math.round((new Date(status.idle.stopAt) - new Date()) / 1000));
+                    text += ' | Idle, stops in ' + Math.floor(seconds / 60) + 'm ' + (seconds % 60) + 's';
+                }
+            }
+            if (status.state === 'Crashed' && status.lastExit) {
+                text += ' | Last exit: ' + status.lastExit.reason;
+            }
+            document.getElementById('serverState').textContent = text;
+            showCountdown(status.countdown);
+        })
+        .catch(() => {
+            document.getElementById('serverState').textContent = 'Server: status unavailable';
+        });
+}
+
+function formatDuration(seconds) {
+    const hours = Math.floor(seconds / 3600);
+    const minutes = Math.floor((seconds % 3600) / 60);
+    return hours + 'h ' + minutes + 'm';
+}
+
 function fetchOutput() {
     const eventSource = new EventSource('/output');
     eventSource.onmessage = function(event) {
@@ -59,6 +160,212 @@ function fetchOutput() {
     };
 }
 
+const maxEvents = 50;
+
+// fetchEvents lists server events as they happen, newest first
+function fetchEvents() {
+    const eventSource = new EventSource('/api/events?replay=20');
+    ['ServerLoading', 'ServerReady', 'PlayerConnecting', 'PlayerReady', 'PlayerDisconnected', 'WorldSaved', 'Exception', 'ProcessExited'].forEach(type => {
+        eventSource.addEventListener(type, message => {
+            const e = JSON.parse(message.data);
+            const eventList = document.getElementById('eventList');
+            const listItem = document.createElement('li');
+            listItem.textContent = new Date(e.time).toLocaleTimeString() + ' ' + describeEvent(e);
+            if (e.type === 'Exception' || e.crashed) {
+                listItem.classList.add('exception-line');
+            }
+            eventList.insertBefore(listItem, eventList.firstChild);
+            while (eventList.children.length > maxEvents) {
+                eventList.removeChild(eventList.lastChild);
+            }
+            if (e.type === 'Exception') {
+                fetchExceptions();
+            }
+            if (e.type === 'ProcessExited') {
+                fetchPolicyActions();
+            }
+            if (e.type.startsWith('Player') || e.type === 'ProcessExited' || e.type === 'ServerReady') {
+                fetchStatus();
+            }
+        });
+    });
+}
+
+function describeEvent(e) {
+    switch (e.type) {
+        case 'ServerLoading': return 'Server is loading';
+        case 'ServerReady': return 'Server is ready to connect';
+        case 'PlayerConnecting': return e.player + ' (' + e.steamId + ') is connecting';
+        case 'PlayerReady': return e.player + ' (' + e.steamId + ') joined';
+        case 'PlayerDisconnected': return e.player + ' (' + e.steamId + ') left';
+        case 'WorldSaved': return 'World saved (backup ' + e.backupIndex + ')';
+        case 'Exception': return 'Exception ' + e.signature + ' (' + e.count + 'x): ' + e.message.split('\n')[0];
+        case 'ProcessExited': return (e.crashed ? 'Server crashed: ' : 'Server exited: ') + (e.message || 'exit code ' + e.exitCode);
+        default: return e.type;
+    }
+}
+
+const resourceWindow = 60 * 60 * 1000; // the charts show the last hour, as much as the controller keeps
+let resourceSamples = [];
+
+// fetchResources loads the resource history of the server and keeps the charts current from the stream
+function fetchResources() {
+    fetch('/api/resources?minutes=60')
+        .then(response => response.json())
+        .then(data => {
+            if (!data.available) {
+                document.getElementById('resourceSummary').textContent = 'Resource monitoring needs /proc and is only available on Linux.';
+                document.getElementById('resourceCharts').style.display = 'none';
+                return;
+            }
+            resourceSamples = data.samples;
+            drawResources(data.intervalSeconds);
+            const eventSource = new EventSource('/api/resources/stream');
+            eventSource.onmessage = function(event) {
+                resourceSamples.push(JSON.parse(event.data));
+                const cutoff = Date.now() - resourceWindow;
+                while (resourceSamples.length > 0 && new Date(resourceSamples[0].time).getTime() < cutoff) {
+                    resourceSamples.shift();
+                }
+                drawResources(data.intervalSeconds);
+            };
+        });
+}
+
+function drawResources(intervalSeconds) {
+    const summary = document.getElementById('resourceSummary');
+    const latest = resourceSamples[resourceSamples.length - 1];
+    if (!latest) {
+        summary.textContent = 'No samples yet, they are taken every ' + intervalSeconds + 's while the server runs.';
+    } else {
+        summary.textContent = 'PID ' + latest.pid + ': ' + latest.cpuPercent.toFixed(0) + '% CPU, ' + formatSize(latest.rssBytes) + ' memory, '
+            + latest.threads + ' threads, ' + latest.openFiles + ' open files (' + new Date(latest.time).toLocaleTimeString() + ')';
+    }
+    // A gap longer than two intervals means the server was down, the line is broken there
+    const gap = intervalSeconds * 2000;
+    drawChart('cpuChart', 'CPU %', [{ value: s => s.cpuPercent, color: '#00FFAB' }], v => v.toFixed(0) + '%', gap);
+    drawChart('memoryChart', 'Memory', [{ value: s => s.rssBytes, color: '#00FFAB' }], formatSize, gap);
+    drawChart('playersChart', 'Players', [{ value: s => s.players, color: '#00FFAB' }], v => v.toFixed(0), gap);
+    drawChart('ioChart', 'Disk read / write per second', [
+        { value: s => s.readBytesPerSec, color: '#00FFAB' },
+        { value: s => s.writeBytesPerSec, color: '#FF5555' }
+    ], formatSize, gap);
+}
+
+// drawChart draws the series of the last hour as lines, time on the x axis and zero at the bottom
+function drawChart(id, title, series, format, gap) {
+    const canvas = document.getElementById(id);
+    const ctx = canvas.getContext('2d');
+    const pad = { left: 10, right: 10, top: 24, bottom: 10 };
+    const width = canvas.width - pad.left - pad.right;
+    const height = canvas.height - pad.top - pad.bottom;
+    const end = Date.now();
+    const start = end - resourceWindow;
+
+    let max = 0;
+    resourceSamples.forEach(sample => series.forEach(line => max = Math.max(max, line.value(sample))));
+    if (max === 0) {
+        max = 1;
+    }
+
+    ctx.clearRect(0, 0, canvas.width, canvas.height);
+    ctx.fillStyle = '#00FFAB';
+    ctx.font = '12px Courier New, monospace';
+    ctx.fillText(title + ' (max ' + format(max) + ')', pad.left, 16);
+
+    series.forEach(line => {
+        ctx.strokeStyle = line.color;
+        ctx.lineWidth = 2;
+        ctx.beginPath();
+        let previous = null;
+        resourceSamples.forEach(sample => {
+            const time = new Date(sample.time).getTime();
+            const x = pad.left + (time - start) / resourceWindow * width;
+            const y = pad.top + height - line.value(sample) / max * height;
+            if (previous === null || time - previous > gap) {
+                ctx.moveTo(x, y);
+            } else {
+                ctx.lineTo(x, y);
+            }
+            previous = time;
+        });
+        ctx.stroke();
+    });
+}
+
+// fetchExceptions lists the server exceptions grouped by stack signature
+function fetchExceptions() {
+    fetch('/api/exceptions')
+        .then(response => response.json())
+        .then(groups => {
+            const exceptionList = document.getElementById('exceptionList');
+            exceptionList.innerHTML = '';
+            if (groups.length === 0) {
+                exceptionList.textContent = 'No exceptions since the controller started.';
+                return;
+            }
+            groups.forEach(group => {
+                const listItem = document.createElement('li');
+                const details = document.createElement('details');
+                const summary = document.createElement('summary');
+                summary.textContent = group.message + ' - ' + group.count + 'x (' + group.windowCount + 'x in the last hour), first seen '
+                    + new Date(group.firstSeen).toLocaleString() + ', last seen ' + new Date(group.lastSeen).toLocaleString();
+                summary.classList.add('exception-line');
+                const trace = document.createElement('pre');
+                trace.textContent = group.sample;
+                details.appendChild(summary);
+                details.appendChild(trace);
+                listItem.appendChild(details);
+                exceptionList.appendChild(listItem);
+            });
+        });
+}
+
+// fetchPolicyActions lists what the exception policies did automatically and why
+function fetchPolicyActions() {
+    fetch('/api/policies')
+        .then(response => response.json())
+        .then(data => {
+            const actionList = document.getElementById('policyActionList');
+            actionList.innerHTML = '';
+            if (data.actions.length === 0) {
+                actionList.textContent = data.policies.length + ' exception policies active, no automatic actions so far.';
+                return;
+            }
+            data.actions.forEach(action => {
+                const listItem = document.createElement('li');
+                const details = document.createElement('details');
+                const summary = document.createElement('summary');
+                summary.textContent = new Date(action.time).toLocaleString() + ' ' + action.policy + ': ' + action.action + ' after '
+                    + action.occurrences.length + ' exceptions within ' + action.window + ' (' + action.result + ')';
+                const trace = document.createElement('pre');
+                trace.textContent = 'Signature ' + action.signature + '\n' + action.trace;
+                details.appendChild(summary);
+                details.appendChild(trace);
+                listItem.appendChild(details);
+                actionList.appendChild(listItem);
+            });
+        });
+}
+
+let currentPermissions = [];
+
+// fetchCurrentUser shows who is logged in and hides the controls the role may not use
+function fetchCurrentUser() {
+    fetch('/api/me')
+        .then(response => response.json())
+        .then(me => {
+            currentPermissions = me.permissions;
+            document.getElementById('currentUser').textContent = 'Logged in as ' + me.username + ' (' + me.role + ')';
+            document.querySelectorAll('[data-permission]').forEach(element => {
+                if (!currentPermissions.includes(element.dataset.permission)) {
+                    element.style.display = 'none';
+                }
+            });
+            fetchBackups();
+        });
+}
+
 function fetchBackups() {
     fetch('/backups')
         .then(response => response.text())
@@ -73,7 +380,10 @@ function fetchBackups() {
                     if (backup.trim()) {
                         const listItem = document.createElement('li');
                         listItem.classList.add('backup-item');
-                        listItem.innerHTML = backup + ' <button onclick="restoreBackup(' + extractIndex(backup) + ')">Restore</button>';
+                        listItem.innerHTML = backup;
+                        if (currentPermissions.includes('backups:restore')) {
+                            listItem.innerHTML += ' <button onclick="restoreBackup(' + extractIndex(backup) + ')">Restore</button>';
+                        }
                         backupList.appendChild(listItem);
                     }
                 });
@@ -81,6 +391,96 @@ function fetchBackups() {
         });
 }
 
+function fetchLogs() {
+    fetch('/api/logs')
+        .then(response => response.json())
+        .then(files => {
+            const logList = document.getElementById('logList');
+            logList.innerHTML = '';
+            if (files.length === 0) {
+                logList.textContent = 'No server logs yet.';
+                return;
+            }
+            files.forEach(file => {
+                const listItem = document.createElement('li');
+                listItem.classList.add('backup-item');
+                const label = document.createElement('span');
+                label.textContent = file.name + (file.current ? ' (current)' : '') + ' - ' + formatSize(file.size);
+                const button = document.createElement('button');
+                button.textContent = 'Download';
+                button.onclick = () => window.location.href = '/api/logs/download?name=' + encodeURIComponent(file.name);
+                listItem.appendChild(label);
+                listItem.appendChild(button);
+                logList.appendChild(listItem);
+            });
+        });
+}
+
+const searchLimit = 100;
+let searchOffset = 0;
+
+function searchLogs(event, offset) {
+    if (event) {
+        event.preventDefault();
+    }
+    searchOffset = Math.max(offset, 0);
+
+    const params = new URLSearchParams({ offset: searchOffset, limit: searchLimit });
+    const from = document.getElementById('searchFrom').value;
+    const to = document.getElementById('searchTo').value;
+    const text = document.getElementById('searchText').value;
+    const severity = document.getElementById('searchSeverity').value;
+    if (from) {
+        params.set('from', new Date(from).toISOString());
+    }
+    if (to) {
+        params.set('to', new Date(to).toISOString());
+    }
+    if (text) {
+        params.set(document.getElementById('searchRegex').checked ? 'regex' : 'q', text);
+    }
+    if (severity) {
+        params.set('severity', severity);
+    }
+
+    fetch('/api/logs/search?' + params.toString())
+        .then(response => {
+            if (!response.ok) {
+                return response.text().then(message => { throw new Error(message); });
+            }
+            return response.json();
+        })
+        .then(data => {
+            const results = document.getElementById('searchResults');
+            results.innerHTML = '';
+            data.results.forEach(result => {
+                const line = document.createElement('div');
+                line.textContent = new Date(result.time).toLocaleString() + '  ' + result.line;
+                if (result.severity === 'exception') {
+                    line.classList.add('exception-line');
+                }
+                results.appendChild(line);
+            });
+            const last = Math.min(data.offset + data.results.length, data.total);
+            document.getElementById('searchSummary').textContent = data.total === 0
+                ? 'No matching lines.'
+                : 'Showing ' + (data.offset + 1) + '-' + last + ' of ' + data.total + ' matching lines.';
+            document.getElementById('searchPaging').style.display = data.total > searchLimit ? 'flex' : 'none';
+            document.getElementById('searchPrev').disabled = data.offset === 0;
+            document.getElementById('searchNext').disabled = last >= data.total;
+        })
+        .catch(error => {
+            document.getElementById('searchSummary').textContent = error.message;
+        });
+}
+
+function formatSize(bytes) {
+    if (bytes < 1024 * 1024) {
+        return Math.ceil(bytes / 1024) + ' KB';
+    }
+    return (bytes / (1024 * 1024)).toFixed(1) + ' MB';
+}
+
 function extractIndex(backupText) {
     const match = backupText.match(/Index: (\d+)/);
     return match ? match[1] : null;
@@ -173,4 +573,11 @@ function setDefaultConsoleMessage() {
 
 
 fetchOutput();
-fetchBackups();
\ No newline at end of file
+fetchEvents();
+fetchResources();
+fetchExceptions();
+fetchPolicyActions();
+fetchCurrentUser();
+fetchLogs();
+fetchStatus();
+setInterval(fetchStatus, 5000);
\ No newline at end of file
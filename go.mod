@@ -5,14 +5,10 @@ go 1.22.1
 require (
 	github.com/bwmarrin/discordgo v0.28.1
 	github.com/fsnotify/fsnotify v1.7.0
-	github.com/r3labs/sse v0.0.0-20210224172625-26fe804710bc
+	golang.org/x/crypto v0.26.0
 )
 
 require (
 	github.com/gorilla/websocket v1.4.2 // indirect
-	github.com/stretchr/testify v1.7.0 // indirect
-	golang.org/x/crypto v0.26.0 // indirect
-	golang.org/x/net v0.28.0 // indirect
 	golang.org/x/sys v0.23.0 // indirect
-	gopkg.in/cenkalti/backoff.v1 v1.1.0 // indirect
 )
@@ -3,14 +3,21 @@ package api
 import (
 	"net/http"
 	"os/exec"
+	"path"
+	"strings"
 	"sync"
 )
 
+const (
+	outputHistorySize  = 2000 // output lines kept in memory for clients that connect later
+	defaultReplayLines = 200  // lines replayed to a new output client unless it asks for a different amount
+	outputQueueSize    = 256  // lines buffered per output client before its overflow policy applies
+)
+
 var cmd *exec.Cmd
 var mu sync.Mutex
 var outputChannel chan string
-var clients []chan string
-var clientsMu sync.Mutex
+var outputHub = newLogHub(outputHistorySize)
 
 type Config struct {
 	Server struct {
@@ -27,3 +34,15 @@ func StartAPI() {
 func ServeUI(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, "./UIMod/index.html")
 }
+
+// ServeStatic serves the UI assets but never the config files, they contain the Discord token and account data
+func ServeStatic(fs http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.ToLower(path.Ext(r.URL.Path)) {
+		case ".json", ".xml":
+			http.NotFound(w, r)
+			return
+		}
+		fs.ServeHTTP(w, r)
+	})
+}
@@ -152,39 +152,11 @@ func SaveConfig(w http.ResponseWriter, r *http.Request) {
 			settings = append(settings, strings.Split(additionalParams, " ")...)
 		}
 
-		settingsStr := strings.Join(settings, " ")
-
-		// Determine the executable path based on the operating system
-		var exePath string
-		if runtime.GOOS == "windows" {
-			exePath = "./rocketstation_DedicatedServer.exe"
-		} else {
-			exePath = "./rocketstation_DedicatedServer.x86_64"
-		}
-
-		config := Config{
-			Server: struct {
-				ExePath  string `xml:"exePath"`
-				Settings string `xml:"settings"`
-			}{
-				ExePath:  exePath,
-				Settings: settingsStr,
-			},
-			SaveFileName: r.FormValue("saveFileName"),
-		}
-
-		configPath := "./UIMod/config.xml"
-		file, err := os.Create(configPath)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Error creating config file: %v", err), http.StatusInternalServerError)
-			return
-		}
-		defer file.Close()
+		config := Config{SaveFileName: r.FormValue("saveFileName")}
+		config.Server.Settings = strings.Join(settings, " ")
 
-		encoder := xml.NewEncoder(file)
-		encoder.Indent("", "  ")
-		if err := encoder.Encode(config); err != nil {
-			http.Error(w, fmt.Sprintf("Error encoding config: %v", err), http.StatusInternalServerError)
+		if err := saveGameConfig(config); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
@@ -193,3 +165,27 @@ func SaveConfig(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
 	}
 }
+
+// saveGameConfig writes the game server config to the XML file, the executable path always matches the platform
+func saveGameConfig(config Config) error {
+	// Determine the executable path based on the operating system
+	if runtime.GOOS == "windows" {
+		config.Server.ExePath = "./rocketstation_DedicatedServer.exe"
+	} else {
+		config.Server.ExePath = "./rocketstation_DedicatedServer.x86_64"
+	}
+
+	configPath := "./UIMod/config.xml"
+	file, err := os.Create(configPath)
+	if err != nil {
+		return fmt.Errorf("error creating config file: %v", err)
+	}
+	defer file.Close()
+
+	encoder := xml.NewEncoder(file)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(config); err != nil {
+		return fmt.Errorf("error encoding config: %v", err)
+	}
+	return nil
+}
@@ -2,6 +2,9 @@ package api
 
 import (
 	"StationeersServerUI/src/discord"
+	"StationeersServerUI/src/lifecycle"
+	"StationeersServerUI/src/service"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -23,23 +26,26 @@ type backupGroup struct {
 	modTime  time.Time
 }
 
-func ListBackups(w http.ResponseWriter, r *http.Request) {
+var (
+	errBackupNotFound = service.ErrBackupNotFound
+	errCannotRestore  = service.ErrCannotRestore
+)
+
+// listBackups returns the backups in the Safebackups folder, newest index first
+func listBackups() ([]service.BackupInfo, error) {
 	config, err := loadConfig()
 	if err != nil {
-		http.Error(w, "Error loading config", http.StatusInternalServerError)
-		return
+		return nil, fmt.Errorf("error loading config: %v", err)
 	}
 
 	// Read from the Safebackups folder
 	safeBackupDir := "./saves/" + config.SaveFileName + "/Safebackups"
 	files, err := os.ReadDir(safeBackupDir)
 	if err != nil {
-		http.Error(w, "Unable to read Safebackups directory", http.StatusInternalServerError)
-		return
+		return nil, fmt.Errorf("unable to read Safebackups directory: %v", err)
 	}
 
 	backupDetails := make(map[int]time.Time)
-	backupIndices := make(map[int]bool)
 
 	for _, file := range files {
 		if file.IsDir() {
@@ -49,39 +55,38 @@ func ListBackups(w http.ResponseWriter, r *http.Request) {
 		fileName := file.Name()
 		backupIndex := parseBackupIndex(fileName)
 		if backupIndex != -1 {
-			if !backupIndices[backupIndex] {
-				backupIndices[backupIndex] = true
-				fullPath := filepath.Join(safeBackupDir, fileName)
-				info, err := os.Stat(fullPath)
+			if _, seen := backupDetails[backupIndex]; !seen {
+				info, err := file.Info()
 				if err != nil {
-					http.Error(w, "Error getting file info", http.StatusInternalServerError)
-					return
+					return nil, fmt.Errorf("error getting file info: %v", err)
 				}
 				backupDetails[backupIndex] = info.ModTime()
 			}
 		}
 	}
 
-	var sortedBackups []struct {
-		index   int
-		modTime time.Time
-	}
-
+	backups := []service.BackupInfo{}
 	for index, modTime := range backupDetails {
-		sortedBackups = append(sortedBackups, struct {
-			index   int
-			modTime time.Time
-		}{index, modTime})
+		backups = append(backups, service.BackupInfo{Index: index, Created: modTime})
 	}
 
-	sort.Slice(sortedBackups, func(i, j int) bool {
-		return sortedBackups[i].index > sortedBackups[j].index // Sort by descending index
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Index > backups[j].Index // Sort by descending index
 	})
+	return backups, nil
+}
+
+func ListBackups(w http.ResponseWriter, r *http.Request) {
+	backups, err := listBackups()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	var output []string
-	for _, backup := range sortedBackups {
-		creationTime := backup.modTime.Format("02.01.2006 15:04:05")
-		output = append(output, fmt.Sprintf("BackupIndex: %d, Created: %s", backup.index, creationTime))
+	for _, backup := range backups {
+		creationTime := backup.Created.Format("02.01.2006 15:04:05")
+		output = append(output, fmt.Sprintf("BackupIndex: %d, Created: %s", backup.Index, creationTime))
 	}
 
 	if len(output) == 0 {
@@ -141,11 +146,31 @@ func RestoreBackup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = restoreBackup(index)
+	switch {
+	case errors.Is(err, errCannotRestore):
+		http.Error(w, fmt.Sprintf("%v. Stop the server first.", err), http.StatusConflict)
+	case errors.Is(err, errBackupNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		fmt.Fprintf(w, "Backup %d restored successfully.", index)
+	}
+}
+
+// restoreBackup copies the files of a backup over the current save, the server must be stopped
+func restoreBackup(index int) error {
 	config, err := loadConfig()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error loading config: %v", err), http.StatusInternalServerError)
-		return
+		return fmt.Errorf("error loading config: %v", err)
+	}
+
+	// Restoring over the files of a running server would be overwritten by its next save
+	if err := lifecycle.Transition(lifecycle.Restoring); err != nil {
+		return fmt.Errorf("%w: %v", errCannotRestore, err)
 	}
+	defer lifecycle.Transition(lifecycle.Stopped)
 
 	// Use the Safebackups folder for restoring
 	safeBackupDir := "./saves/" + config.SaveFileName + "/Safebackups"
@@ -160,6 +185,15 @@ func RestoreBackup(w http.ResponseWriter, r *http.Request) {
 		{fmt.Sprintf("world(%d).bin", index), fmt.Sprintf("world(%d)_AutoSave.bin", index), "world.bin"},
 	}
 
+	// Refuse unknown indices before touching the save
+	for _, file := range files {
+		_, err := os.Stat(filepath.Join(safeBackupDir, file.backupName))
+		_, errAlt := os.Stat(filepath.Join(safeBackupDir, file.backupNameAlt))
+		if err != nil && errAlt != nil {
+			return fmt.Errorf("%w: %s", errBackupNotFound, file.backupName)
+		}
+	}
+
 	// Create a map to store successful restore operations
 	restoredFiles := make(map[string]string)
 
@@ -176,15 +210,15 @@ func RestoreBackup(w http.ResponseWriter, r *http.Request) {
 			if errAlt != nil {
 				// Revert any successful operations if an error occurs
 				revertRestore(restoredFiles, saveDir, safeBackupDir)
-				http.Error(w, fmt.Sprintf("Error restoring file %s and %s: %v", file.backupName, file.backupNameAlt, err), http.StatusInternalServerError)
-				return
+				return fmt.Errorf("error restoring file %s and %s: %v", file.backupName, file.backupNameAlt, err)
 			}
 			backupFile = backupFileAlt
 		}
 		restoredFiles[destFile] = backupFile
 	}
 
-	fmt.Fprintf(w, "Backup %d restored successfully.", index)
+	fmt.Printf("Backup %d restored.\n", index)
+	return nil
 }
 
 // copyFile copies a file from src to dst. If dst already exists, it will be overwritten. This is the inteded behavior of the restoreBackup function. We overwrite the destination files with the backup files
@@ -230,6 +264,8 @@ func revertRestore(restoredFiles map[string]string, saveDir, backupDir string) {
 }
 
 func WatchBackupDir() {
+	defer close(backupWatcherDone)
+
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		fmt.Println("Error creating watcher:", err)
@@ -264,9 +300,11 @@ func WatchBackupDir() {
 		return
 	}
 
-	// Watch for events in the backup directory
+	// Watch for events in the backup directory until the controller shuts down
 	for {
 		select {
+		case <-shuttingDown:
+			return
 		case event, ok := <-watcher.Events:
 			if !ok {
 				fmt.Println("Watcher closed.")
@@ -274,7 +312,7 @@ func WatchBackupDir() {
 			}
 			if event.Op&fsnotify.Create == fsnotify.Create {
 				fmt.Println("New backup file detected:", event.Name)
-				go copyBackupToSafeLocation(event.Name, safeBackupDir)
+				copyBackupToSafeLocation(event.Name, safeBackupDir)
 			}
 
 		case err, ok := <-watcher.Errors:
@@ -287,11 +325,18 @@ func WatchBackupDir() {
 	}
 }
 
-// Copy the detected backup file to a safe location
+// Copy the detected backup file to a safe location. Only the watcher calls this, so Shutdown can wait
+// for the watcher to exit before it waits for the copies.
 func copyBackupToSafeLocation(srcFilePath string, safeBackupDir string) {
+	backupCopies.Add(1)
 	go func() {
-		// Introduce a 1-minute asynchronous wait before trying to copy the file
-		time.Sleep(30 * time.Second)
+		defer backupCopies.Done()
+		// Introduce a 1-minute asynchronous wait before trying to copy the file,
+		// on shutdown the server has stopped writing and the copy happens right away
+		select {
+		case <-time.After(30 * time.Second):
+		case <-shuttingDown:
+		}
 
 		fileName := filepath.Base(srcFilePath)
 		dstFilePath := filepath.Join(safeBackupDir, fileName)
@@ -310,6 +355,7 @@ func copyBackupToSafeLocation(srcFilePath string, safeBackupDir string) {
 			return
 		}
 
+		backupFilesCopied.Add(1)
 		fmt.Println("Backup successfully copied to safe location:", dstFilePath)
 		discord.SendMessageToSavesChannel(fmt.Sprintf("Backup file %s copied to safe location.", dstFilePath))
 	}()
@@ -353,6 +399,8 @@ func cleanBackupFolder(backupDir string, maxAge time.Duration) error {
 			err = os.Remove(fullPath)
 			if err != nil {
 				fmt.Printf("Error removing file %s: %v\n", fullPath, err)
+			} else {
+				backupFilesPruned.Add(1)
 			}
 		}
 	}
@@ -463,6 +511,7 @@ func deleteBackupFiles(backup backupGroup) {
 		if err != nil {
 			fmt.Printf("Error removing .bin file %s in deleteBackupFiles: %v\n", backup.binFile, err)
 		} else {
+			safebackupsPruned.Add(1)
 			fmt.Printf("Removed .bin file: %s\n", backup.binFile)
 		}
 	}
@@ -472,6 +521,7 @@ func deleteBackupFiles(backup backupGroup) {
 		if err != nil {
 			fmt.Printf("Error removing .xml file %s in deleteBackupFiles: %v\n", backup.xmlFile, err)
 		} else {
+			safebackupsPruned.Add(1)
 			fmt.Printf("Removed .xml file: %s\n", backup.xmlFile)
 		}
 	}
@@ -481,6 +531,7 @@ func deleteBackupFiles(backup backupGroup) {
 		if err != nil {
 			fmt.Printf("Error removing meta file %s in deleteBackupFiles: %v\n", backup.metaFile, err)
 		} else {
+			safebackupsPruned.Add(1)
 			fmt.Printf("Removed meta file: %s\n", backup.metaFile)
 		}
 	}
@@ -499,7 +550,12 @@ func StartBackupCleanupRoutine() {
 	safeBackupDir := "./saves/" + config.SaveFileName + "/Safebackups"
 	backupDir := "./saves/" + config.SaveFileName + "/backup"
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ticker.C:
+		case <-shuttingDown:
+			return
+		}
 		fmt.Println("Starting backup cleanup...")
 
 		// Check if the backup directory exists, if not log and continue
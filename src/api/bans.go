@@ -0,0 +1,128 @@
+package api
+
+import (
+	"StationeersServerUI/src/config"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// banMu serializes changes to the blacklist file
+var banMu sync.Mutex
+
+// readBlacklist returns the banned SteamIDs, a missing file means nobody is banned
+func readBlacklist() ([]string, error) {
+	data, err := os.ReadFile(config.BlackListFilePath)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, entry := range strings.Split(string(data), ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			ids = append(ids, entry)
+		}
+	}
+	return ids, nil
+}
+
+func writeBlacklist(ids []string) error {
+	return os.WriteFile(config.BlackListFilePath, []byte(strings.Join(ids, ",")), 0644)
+}
+
+// setBanned adds or removes a SteamID from the blacklist and reports whether anything changed
+func setBanned(steamID string, banned bool) (bool, error) {
+	banMu.Lock()
+	defer banMu.Unlock()
+
+	ids, err := readBlacklist()
+	if err != nil {
+		return false, err
+	}
+
+	updated := []string{}
+	found := false
+	for _, id := range ids {
+		if id == steamID {
+			found = true
+			continue
+		}
+		updated = append(updated, id)
+	}
+	if found == banned {
+		return false, nil
+	}
+	if banned {
+		updated = append(updated, steamID)
+	}
+	return true, writeBlacklist(updated)
+}
+
+func validSteamID(steamID string) bool {
+	if len(steamID) == 0 || len(steamID) > 20 {
+		return false
+	}
+	for _, r := range steamID {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ListBans serves the banned SteamIDs as JSON
+func ListBans(w http.ResponseWriter, r *http.Request) {
+	ids, err := readBlacklist()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error reading blacklist: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ids)
+}
+
+// BanPlayer adds the SteamID from the steamid parameter to the blacklist
+func BanPlayer(w http.ResponseWriter, r *http.Request) {
+	handleBan(w, r, true)
+}
+
+// UnbanPlayer removes the SteamID from the steamid parameter from the blacklist
+func UnbanPlayer(w http.ResponseWriter, r *http.Request) {
+	handleBan(w, r, false)
+}
+
+func handleBan(w http.ResponseWriter, r *http.Request, banned bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	steamID := strings.TrimSpace(r.FormValue("steamid"))
+	if !validSteamID(steamID) {
+		http.Error(w, "Invalid SteamID, expected the numeric SteamID64", http.StatusBadRequest)
+		return
+	}
+
+	changed, err := setBanned(steamID, banned)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error updating blacklist: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	switch {
+	case banned && !changed:
+		fmt.Fprintf(w, "SteamID %s is already banned.", steamID)
+	case banned:
+		fmt.Fprintf(w, "SteamID %s has been banned.", steamID)
+	case !changed:
+		fmt.Fprintf(w, "SteamID %s is not banned.", steamID)
+	default:
+		fmt.Fprintf(w, "SteamID %s has been unbanned.", steamID)
+	}
+}
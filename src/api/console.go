@@ -0,0 +1,190 @@
+package api
+
+import (
+	"StationeersServerUI/src/auth"
+	"StationeersServerUI/src/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	consoleAuditFile     = "./logs/console-audit.log"
+	consoleAuditKeepLast = 100 // entries kept in memory for the audit endpoint
+)
+
+var errCommandNotAllowed = service.ErrCommandNotAllowed
+
+var errNoConsole = errors.New("the console of the re-attached server cannot be reached, set detachServer to keep it across controller restarts")
+
+// consoleAuditEntry records who sent which command to the server console
+type consoleAuditEntry struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"` // web, api, discord, scheduler or system
+	User    string    `json:"user"`
+	Command string    `json:"command"`
+	Result  string    `json:"result"`
+}
+
+var (
+	stdinMu     sync.Mutex
+	cmdStdin    io.WriteCloser
+	consoleLost bool // the server runs, but was re-attached without its console
+	auditMu     sync.Mutex
+	recentAudit []consoleAuditEntry
+)
+
+// attachConsole takes ownership of the stdin pipe of a newly started server, nil for a re-attached server without console
+func attachConsole(stdin io.WriteCloser) {
+	stdinMu.Lock()
+	defer stdinMu.Unlock()
+	cmdStdin = stdin
+	consoleLost = stdin == nil
+}
+
+// detachConsole closes the stdin pipe of a server that has exited
+func detachConsole() {
+	stdinMu.Lock()
+	defer stdinMu.Unlock()
+
+	if cmdStdin != nil {
+		cmdStdin.Close()
+		cmdStdin = nil
+	}
+	consoleLost = false
+}
+
+// SendConsoleCommand checks a command for control characters and against the allowlist, records it in the audit trail and types it into the server console
+func SendConsoleCommand(source, user, command string) error {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return errors.New("command is empty")
+	}
+	if err := service.CheckConsoleCommand(command); err != nil {
+		auditConsoleCommand(source, user, command, err)
+		return err
+	}
+
+	if !service.ConsoleCommandAllowed(command) {
+		auditConsoleCommand(source, user, command, errCommandNotAllowed)
+		return errCommandNotAllowed
+	}
+
+	err := writeServerCommand(command)
+	auditConsoleCommand(source, user, command, err)
+	return err
+}
+
+// sendSystemCommand types a command on behalf of the controller itself, bypassing the allowlist
+func sendSystemCommand(command string) error {
+	err := writeServerCommand(command)
+	auditConsoleCommand("system", "StationeersServerControl", command, err)
+	return err
+}
+
+func writeServerCommand(command string) error {
+	stdinMu.Lock()
+	defer stdinMu.Unlock()
+
+	if cmdStdin == nil {
+		if consoleLost {
+			return errNoConsole
+		}
+		return errServerNotRunning
+	}
+	_, err := io.WriteString(cmdStdin, command+"\n")
+	return err
+}
+
+func auditConsoleCommand(source, user, command string, err error) {
+	entry := consoleAuditEntry{
+		Time:    time.Now(),
+		Source:  source,
+		User:    user,
+		Command: command,
+		Result:  "sent",
+	}
+	if err != nil {
+		entry.Result = err.Error()
+	}
+	fmt.Printf("Console command from %s (%s): %s -> %s\n", entry.User, entry.Source, entry.Command, entry.Result)
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	recentAudit = append(recentAudit, entry)
+	if len(recentAudit) > consoleAuditKeepLast {
+		recentAudit = recentAudit[len(recentAudit)-consoleAuditKeepLast:]
+	}
+
+	if err := os.MkdirAll(filepath.Dir(consoleAuditFile), os.ModePerm); err != nil {
+		fmt.Println("Error creating logs directory:", err)
+		return
+	}
+	file, err := os.OpenFile(consoleAuditFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Println("Error opening console audit log:", err)
+		return
+	}
+	defer file.Close()
+	json.NewEncoder(file).Encode(entry)
+}
+
+// HandleConsole accepts console commands as JSON {"command": "..."} or form data, commands are audited under the logged in user
+func HandleConsole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Command string `json:"command"`
+		Source  string `json:"source"`
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+	} else {
+		request.Command = r.FormValue("command")
+		request.Source = r.FormValue("source")
+	}
+	if request.Source != "web" {
+		request.Source = "api"
+	}
+	user := auth.Username(r)
+	if user == "" {
+		user = r.RemoteAddr
+	}
+
+	err := SendConsoleCommand(request.Source, user, request.Command)
+	switch {
+	case errors.Is(err, errCommandNotAllowed):
+		http.Error(w, fmt.Sprintf("Command rejected: %v", err), http.StatusForbidden)
+	case errors.Is(err, errServerNotRunning):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		http.Error(w, fmt.Sprintf("Error sending command: %v", err), http.StatusBadRequest)
+	default:
+		fmt.Fprintf(w, "Command sent: %s", strings.TrimSpace(request.Command))
+	}
+}
+
+// GetConsoleAudit serves the most recent console commands as JSON
+func GetConsoleAudit(w http.ResponseWriter, r *http.Request) {
+	auditMu.Lock()
+	entries := make([]consoleAuditEntry, len(recentAudit))
+	copy(entries, recentAudit)
+	auditMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"StationeersServerUI/src/auth"
+	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	errCountdownActive = service.ErrCountdownActive
+	errNoCountdown     = service.ErrNoCountdown
+	errCountdownFinal  = service.ErrCountdownFinal
+)
+
+// restartCountdown is a restart that warns the players in game before it happens
+type restartCountdown struct {
+	RestartAt   time.Time `json:"restartAt"`
+	Reason      string    `json:"reason"`
+	RequestedBy string    `json:"requestedBy"`
+	Final       bool      `json:"final"` // the final warning has been sent, the restart can no longer be cancelled
+	cancel      chan struct{}
+}
+
+var (
+	countdownMu sync.Mutex
+	countdown   *restartCountdown
+)
+
+// restartWarnings parses the configured warning times, longest first
+func restartWarnings() []time.Duration {
+	var warnings []time.Duration
+	for _, warning := range config.RestartWarnings {
+		d, err := time.ParseDuration(strings.TrimSpace(warning))
+		if err != nil || d <= 0 {
+			fmt.Printf("⚠️Ignoring restart warning %q, expected a duration like 15m or 10s\n", warning)
+			continue
+		}
+		warnings = append(warnings, d)
+	}
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i] > warnings[j] })
+	return warnings
+}
+
+// startCountdownRestart schedules a restart after the longest warning and announces it in game at every warning
+func startCountdownRestart(reason, requestedBy string) (time.Time, error) {
+	mu.Lock()
+	running := cmd != nil && cmd.Process != nil
+	mu.Unlock()
+	if !running {
+		return time.Time{}, errServerNotRunning
+	}
+
+	warnings := restartWarnings()
+	var lead time.Duration
+	if len(warnings) > 0 {
+		lead = warnings[0]
+	}
+
+	countdownMu.Lock()
+	if countdown != nil {
+		restartAt := countdown.RestartAt
+		countdownMu.Unlock()
+		return restartAt, errCountdownActive
+	}
+	c := &restartCountdown{
+		RestartAt:   time.Now().Add(lead),
+		Reason:      reason,
+		RequestedBy: requestedBy,
+		cancel:      make(chan struct{}),
+	}
+	countdown = c
+	countdownMu.Unlock()
+
+	notifySupervisor(fmt.Sprintf("🔄 %s requested a restart in %s (%s). It can be cancelled until the final warning.", requestedBy, formatWarning(lead), reason))
+	go runCountdown(c, warnings)
+	return c.RestartAt, nil
+}
+
+// runCountdown sends the warnings and restarts the server unless the countdown is cancelled before the final warning
+func runCountdown(c *restartCountdown, warnings []time.Duration) {
+	for i, warning := range warnings {
+		select {
+		case <-time.After(time.Until(c.RestartAt.Add(-warning))):
+		case <-c.cancel:
+			return
+		}
+
+		countdownMu.Lock()
+		if countdown != c {
+			countdownMu.Unlock()
+			return
+		}
+		// Once the players were told the last time, the restart goes ahead
+		c.Final = i == len(warnings)-1
+		countdownMu.Unlock()
+
+		message := strings.ReplaceAll(config.RestartWarningMessage, "{time}", formatWarning(warning))
+		if err := sendSystemCommand("say " + message); err != nil {
+			fmt.Printf("Error sending restart warning: %v\n", err)
+		}
+	}
+
+	select {
+	case <-time.After(time.Until(c.RestartAt)):
+	case <-c.cancel:
+		return
+	}
+
+	// The countdown ends here, so the stop that is part of the restart does not end it early
+	countdownMu.Lock()
+	if countdown != c {
+		countdownMu.Unlock()
+		return
+	}
+	countdown = nil
+	countdownMu.Unlock()
+
+	restartAfterCountdown(c)
+}
+
+func restartAfterCountdown(c *restartCountdown) {
+	mu.Lock()
+	running := cmd != nil && cmd.Process != nil
+	mu.Unlock()
+	// A server that was stopped during the countdown stays stopped
+	if !running {
+		notifySupervisor("ℹ️ Countdown restart skipped, the server is no longer running.")
+		return
+	}
+
+	notifySupervisor(fmt.Sprintf("🔄 Restarting the server now (%s).", c.Reason))
+	// The stop sequence saves the world first. With saving on stop turned off the players
+	// were still promised a restart, not a rollback, so save with the plain save command.
+	if config.StopSaveCommand == "" {
+		if err := saveWorld(); err != nil {
+			fmt.Printf("Error saving the world before the restart: %v\n", err)
+		}
+	}
+	if _, err := (localServer{}).Restart(); err != nil {
+		notifySupervisor(fmt.Sprintf("❌ Countdown restart failed: %v", err))
+	}
+}
+
+// cancelCountdownRestart cancels a running countdown as long as the final warning has not been sent
+func cancelCountdownRestart(requestedBy string) error {
+	countdownMu.Lock()
+	c := countdown
+	if c == nil {
+		countdownMu.Unlock()
+		return errNoCountdown
+	}
+	if c.Final {
+		countdownMu.Unlock()
+		return errCountdownFinal
+	}
+	countdown = nil
+	close(c.cancel)
+	countdownMu.Unlock()
+
+	if err := sendSystemCommand("say The restart has been cancelled."); err != nil {
+		fmt.Printf("Error announcing the cancelled restart: %v\n", err)
+	}
+	notifySupervisor(fmt.Sprintf("✅ %s cancelled the restart.", requestedBy))
+	return nil
+}
+
+// endCountdown drops a running countdown when the server stops or exits without it, even after the final warning,
+// so the warnings stop and a server started again before the deadline is not restarted
+func endCountdown(why string) {
+	countdownMu.Lock()
+	c := countdown
+	if c == nil {
+		countdownMu.Unlock()
+		return
+	}
+	countdown = nil
+	close(c.cancel)
+	countdownMu.Unlock()
+
+	notifySupervisor(fmt.Sprintf("ℹ️ Restart countdown ended, %s.", why))
+}
+
+// currentCountdown returns a copy of the running countdown, if any
+func currentCountdown() *restartCountdown {
+	countdownMu.Lock()
+	defer countdownMu.Unlock()
+	if countdown == nil {
+		return nil
+	}
+	copied := *countdown
+	return &copied
+}
+
+// formatWarning writes a warning time the way players read it, e.g. "5 minutes" or "10 seconds"
+func formatWarning(d time.Duration) string {
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return plural(int64(d/time.Hour), "hour")
+	case d >= time.Minute && d%time.Minute == 0:
+		return plural(int64(d/time.Minute), "minute")
+	default:
+		return plural(int64(d.Round(time.Second)/time.Second), "second")
+	}
+}
+
+// StartCountdownRestart starts a restart with in-game warnings, ?reason= is shown in the notifications
+func StartCountdownRestart(w http.ResponseWriter, r *http.Request) {
+	reason := r.URL.Query().Get("reason")
+	if reason == "" {
+		reason = "manual restart"
+	}
+	restartAt, err := startCountdownRestart(reason, auth.Username(r))
+	if errors.Is(err, errServerNotRunning) || errors.Is(err, errCountdownActive) {
+		writeJSONError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, messageResponse{Message: "Server restarts at " + restartAt.Format(time.RFC3339) + "."})
+}
+
+// CancelCountdownRestart cancels a restart with warnings until the final warning
+func CancelCountdownRestart(w http.ResponseWriter, r *http.Request) {
+	err := cancelCountdownRestart(auth.Username(r))
+	switch {
+	case errors.Is(err, errNoCountdown):
+		writeJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, errCountdownFinal):
+		writeJSONError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, messageResponse{Message: "Restart cancelled."})
+	}
+}
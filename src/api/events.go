@@ -0,0 +1,153 @@
+package api
+
+import (
+	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/discord"
+	"StationeersServerUI/src/events"
+	"StationeersServerUI/src/lifecycle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const eventQueueSize = 64 // events queued per /api/events client
+
+// ruleRestartCooldown keeps a rule from restarting the server again while it is still recovering
+const ruleRestartCooldown = 10 * time.Minute
+
+var (
+	ruleRestartMu   sync.Mutex
+	lastRuleRestart time.Time
+)
+
+// publishOutputEvents runs a line of server output against the log rules and carries out their actions
+func publishOutputEvents(output string) {
+	for _, match := range events.MatchLine(output) {
+		for _, action := range match.Actions {
+			switch action.Type {
+			case events.ActionNotify:
+				fmt.Printf("[Rule %s] %s\n", match.Rule, action.Message)
+				if config.IsDiscordEnabled {
+					discord.SendMessageToChannel(action.Channel, action.Message)
+				}
+			case events.ActionRestart:
+				restartForRule(match.Rule)
+			}
+		}
+		publishEvents(match.Events)
+	}
+}
+
+// restartForRule restarts the server in the background, at most once per cooldown
+func restartForRule(rule string) {
+	ruleRestartMu.Lock()
+	if time.Since(lastRuleRestart) < ruleRestartCooldown {
+		ruleRestartMu.Unlock()
+		fmt.Printf("Rule %s asked for a restart, but the server was restarted by a rule less than %s ago\n", rule, ruleRestartCooldown)
+		return
+	}
+	lastRuleRestart = time.Now()
+	ruleRestartMu.Unlock()
+
+	notifySupervisor(fmt.Sprintf("♻️Log rule %s matched, restarting server.", rule))
+	// The restart waits for the output pipes to drain, so it cannot run on the goroutine reading them
+	go func() {
+		if _, err := (localServer{}).Restart(); err != nil {
+			notifySupervisor(fmt.Sprintf("❌Restart requested by log rule %s failed: %v", rule, err))
+		}
+	}()
+}
+
+// publishEvents publishes the events of a matched rule. Player events update the player
+// list before they are published, so every subscriber sees the list they describe.
+func publishEvents(list []events.Event) {
+	for _, e := range list {
+		switch e.Type {
+		case events.ServerReady:
+			// "Ready" shows up in many lines, only the first one after starting counts
+			if !lifecycle.TransitionFrom(lifecycle.Starting, lifecycle.Ready) {
+				continue
+			}
+		case events.Exception:
+			// Published with its signature once the stack trace is complete
+			startException(e.Message)
+			continue
+		case events.PlayerReady:
+			config.ConnectedPlayersMu.Lock()
+			config.ConnectedPlayers[e.SteamID] = e.Player
+			config.ConnectedPlayersMu.Unlock()
+		case events.PlayerDisconnected:
+			config.ConnectedPlayersMu.Lock()
+			delete(config.ConnectedPlayers, e.SteamID)
+			config.ConnectedPlayersMu.Unlock()
+		}
+		events.Publish(e)
+	}
+}
+
+// publishProcessExited reports the end of the server process, nobody is connected to a stopped server
+func publishProcessExited(exit exitRecord) {
+	completeTrace()
+
+	config.ConnectedPlayersMu.Lock()
+	for steamID := range config.ConnectedPlayers {
+		delete(config.ConnectedPlayers, steamID)
+	}
+	config.ConnectedPlayersMu.Unlock()
+
+	events.Publish(events.Event{Type: events.ProcessExited, Time: exit.Time, Message: exit.Reason, ExitCode: exit.Code, Crashed: exit.Crashed})
+}
+
+// playerCount returns how many players are connected
+func playerCount() int {
+	config.ConnectedPlayersMu.Lock()
+	defer config.ConnectedPlayersMu.Unlock()
+	return len(config.ConnectedPlayers)
+}
+
+// GetEvents streams server events as SSE, the event name is the event type and the data is the event as JSON.
+// New clients first receive the recent events, ?replay=0 skips them.
+func GetEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	sub := events.Subscribe("SSE client "+r.RemoteAddr, eventQueueSize)
+	defer events.Unsubscribe(sub)
+	defer trackSSEClient("events")()
+
+	replay := events.Recent()
+	if n, err := strconv.Atoi(r.URL.Query().Get("replay")); err == nil && n >= 0 && n < len(replay) {
+		replay = replay[len(replay)-n:]
+	}
+	for _, e := range replay {
+		writeEvent(w, e)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case e, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			writeEvent(w, e)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e events.Event) {
+	data, _ := json.Marshal(e)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+}
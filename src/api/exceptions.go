@@ -0,0 +1,190 @@
+package api
+
+import (
+	"StationeersServerUI/src/events"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	exceptionWindow     = time.Hour              // occurrences are counted until a group has been quiet this long
+	traceIdleTimeout    = 250 * time.Millisecond // a trace is complete when no frame followed for this long
+	maxTraceLines       = 40                     // frames kept per trace, looping traces can be huge
+	signatureFrameCount = 8                      // frames that make up the signature
+	maxExceptionGroups  = 500                    // the least recently seen groups are forgotten beyond this
+)
+
+// exceptionGroup collects the occurrences of one exception, identified by its normalised stack trace
+type exceptionGroup struct {
+	Signature   string    `json:"signature"`
+	Type        string    `json:"type"`
+	Message     string    `json:"message"`     // first line without timestamp
+	Sample      string    `json:"sample"`      // the latest full trace
+	Count       int       `json:"count"`       // since the controller started
+	WindowCount int       `json:"windowCount"` // since the group was last quiet for the window
+	FirstSeen   time.Time `json:"firstSeen"`
+	LastSeen    time.Time `json:"lastSeen"`
+	WindowStart time.Time `json:"windowStart"`
+}
+
+var (
+	exceptionsMu    sync.Mutex
+	exceptionGroups = make(map[string]*exceptionGroup)
+	pendingTrace    []string    // exception being collected, the first line is the header
+	pendingTimer    *time.Timer // completes the pending trace once no more frames arrive
+)
+
+var (
+	frameLine         = regexp.MustCompile(`^\s+\S|^at\s|^\(wrapper|^Rethrow as`)
+	logTimestamp      = regexp.MustCompile(`^\s*>?\s*\d{2}:\d{2}:\d{2}:\s*`)
+	exceptionTypeName = regexp.MustCompile(`[\w.]*Exception\b`)
+	volatileParts     = []struct {
+		pattern     *regexp.Regexp
+		replacement string
+	}{
+		{regexp.MustCompile(`<[0-9a-fA-F]{16,}>`), "<id>"},
+		{regexp.MustCompile(`0x[0-9a-fA-F]+`), "0x?"},
+		{regexp.MustCompile(`"[^"]*"|'[^']*'`), `"?"`},
+		{regexp.MustCompile(`\d+`), "N"},
+	}
+)
+
+// startException begins collecting the trace of an exception, a trace still pending is completed first
+func startException(header string) {
+	exceptionsMu.Lock()
+	defer exceptionsMu.Unlock()
+
+	completeTraceLocked()
+	pendingTrace = []string{header}
+	pendingTimer = time.AfterFunc(traceIdleTimeout, completeTrace)
+}
+
+// continueException adds a stack frame to the pending trace and reports whether the line was one.
+// Any other line completes the pending trace.
+func continueException(line string) bool {
+	exceptionsMu.Lock()
+	defer exceptionsMu.Unlock()
+
+	if pendingTrace == nil {
+		return false
+	}
+	if !frameLine.MatchString(line) {
+		completeTraceLocked()
+		return false
+	}
+	if len(pendingTrace) < maxTraceLines {
+		pendingTrace = append(pendingTrace, line)
+	}
+	pendingTimer.Reset(traceIdleTimeout)
+	return true
+}
+
+func completeTrace() {
+	exceptionsMu.Lock()
+	defer exceptionsMu.Unlock()
+	completeTraceLocked()
+}
+
+// completeTraceLocked records the pending trace in its group and publishes it, the caller must hold exceptionsMu
+func completeTraceLocked() {
+	if pendingTrace == nil {
+		return
+	}
+	trace := pendingTrace
+	pendingTrace = nil
+	pendingTimer.Stop()
+
+	now := time.Now()
+	header := logTimestamp.ReplaceAllString(trace[0], "")
+	signature := exceptionSignature(header, trace[1:])
+
+	group, ok := exceptionGroups[signature]
+	if !ok {
+		group = &exceptionGroup{Signature: signature, Type: exceptionTypeName.FindString(header), Message: header, FirstSeen: now}
+		exceptionGroups[signature] = group
+	}
+	if now.Sub(group.LastSeen) > exceptionWindow {
+		group.WindowCount = 0
+		group.WindowStart = now
+	}
+	group.Count++
+	group.WindowCount++
+	group.LastSeen = now
+	group.Sample = strings.Join(trace, "\n")
+	// Only now, a new group with its zero LastSeen would be the first to go
+	forgetOldGroupsLocked()
+
+	events.Publish(events.Event{
+		Type:      events.Exception,
+		Time:      now,
+		Message:   group.Sample,
+		Signature: signature,
+		Count:     group.WindowCount,
+	})
+}
+
+// exceptionSignature identifies an exception by its type, message and top frames with volatile parts like
+// addresses, numbers and quoted values replaced, so repeats of the same fault share a signature
+func exceptionSignature(header string, frames []string) string {
+	parts := []string{normaliseTraceLine(header)}
+	for i, frame := range frames {
+		if i == signatureFrameCount {
+			break
+		}
+		parts = append(parts, normaliseTraceLine(frame))
+	}
+	sum := sha1.Sum([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func normaliseTraceLine(line string) string {
+	line = strings.TrimSpace(line)
+	for _, v := range volatileParts {
+		line = v.pattern.ReplaceAllString(line, v.replacement)
+	}
+	return line
+}
+
+// forgetOldGroupsLocked drops the least recently seen groups beyond the limit, the caller must hold exceptionsMu
+func forgetOldGroupsLocked() {
+	if len(exceptionGroups) <= maxExceptionGroups {
+		return
+	}
+	var oldest *exceptionGroup
+	for _, group := range exceptionGroups {
+		if oldest == nil || group.LastSeen.Before(oldest.LastSeen) {
+			oldest = group
+		}
+	}
+	delete(exceptionGroups, oldest.Signature)
+}
+
+// listExceptions returns the exception groups, most recently seen first
+func listExceptions() []exceptionGroup {
+	exceptionsMu.Lock()
+	defer exceptionsMu.Unlock()
+
+	list := make([]exceptionGroup, 0, len(exceptionGroups))
+	for _, group := range exceptionGroups {
+		copied := *group
+		if time.Since(copied.LastSeen) > exceptionWindow {
+			copied.WindowCount = 0
+		}
+		list = append(list, copied)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastSeen.After(list[j].LastSeen)
+	})
+	return list
+}
+
+// GetExceptions serves the exceptions of the server grouped by stack signature, most recently seen first
+func GetExceptions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, listExceptions())
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNewExceptionSurvivesTheGroupLimit(t *testing.T) {
+	exceptionsMu.Lock()
+	previous := exceptionGroups
+	exceptionGroups = make(map[string]*exceptionGroup)
+	for i := 0; i < maxExceptionGroups; i++ {
+		signature := fmt.Sprintf("old%d", i)
+		exceptionGroups[signature] = &exceptionGroup{Signature: signature, Count: 1, LastSeen: time.Now().Add(-time.Minute)}
+	}
+	exceptionsMu.Unlock()
+	t.Cleanup(func() {
+		exceptionsMu.Lock()
+		exceptionGroups = previous
+		exceptionsMu.Unlock()
+	})
+
+	startException("12:00:00: NullReferenceException: Object reference not set to an instance of an object")
+	continueException("  at Assets.Scripts.Objects.Thing.OnDestroy () [0x00000] in <00000000000000000000000000000000>:0")
+	completeTrace()
+
+	groups := listExceptions()
+	if len(groups) != maxExceptionGroups {
+		t.Fatalf("%d groups after the new exception, want %d", len(groups), maxExceptionGroups)
+	}
+	if groups[0].Type != "NullReferenceException" || groups[0].Count != 1 {
+		t.Errorf("most recent group is %+v, want the new NullReferenceException", groups[0])
+	}
+}
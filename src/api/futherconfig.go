@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -58,19 +59,33 @@ func HandleConfigJSON(w http.ResponseWriter, r *http.Request) {
 		isDiscordEnabledFalse = "selected"
 	}
 
+	autoRestartEnabledTrue := ""
+	autoRestartEnabledFalse := ""
+
+	if config.AutoRestartEnabled {
+		autoRestartEnabledTrue = "selected"
+	} else {
+		autoRestartEnabledFalse = "selected"
+	}
+
 	// Replace placeholders in the HTML with actual config values, including the new errorChannelID
 	replacements := map[string]string{
-		"{{discordToken}}":            config.DiscordToken,
-		"{{controlChannelID}}":        config.ControlChannelID,
-		"{{statusChannelID}}":         config.StatusChannelID,
-		"{{connectionListChannelID}}": config.ConnectionListChannelID,
-		"{{logChannelID}}":            config.LogChannelID,
-		"{{saveChannelID}}":           config.SaveChannelID,
-		"{{controlPanelChannelID}}":   config.ControlPanelChannelID,
-		"{{blackListFilePath}}":       config.BlackListFilePath,
-		"{{errorChannelID}}":          config.ErrorChannelID, // New errorChannelID field
-		"{{isDiscordEnabledTrue}}":    isDiscordEnabledTrue,
-		"{{isDiscordEnabledFalse}}":   isDiscordEnabledFalse,
+		"{{discordToken}}":             config.DiscordToken,
+		"{{controlChannelID}}":         config.ControlChannelID,
+		"{{statusChannelID}}":          config.StatusChannelID,
+		"{{connectionListChannelID}}":  config.ConnectionListChannelID,
+		"{{logChannelID}}":             config.LogChannelID,
+		"{{saveChannelID}}":            config.SaveChannelID,
+		"{{controlPanelChannelID}}":    config.ControlPanelChannelID,
+		"{{blackListFilePath}}":        config.BlackListFilePath,
+		"{{errorChannelID}}":           config.ErrorChannelID, // New errorChannelID field
+		"{{isDiscordEnabledTrue}}":     isDiscordEnabledTrue,
+		"{{isDiscordEnabledFalse}}":    isDiscordEnabledFalse,
+		"{{autoRestartEnabledTrue}}":   autoRestartEnabledTrue,
+		"{{autoRestartEnabledFalse}}":  autoRestartEnabledFalse,
+		"{{restartBackoffSeconds}}":    optionalInt(config.RestartBackoffSeconds),
+		"{{restartBackoffMaxSeconds}}": optionalInt(config.RestartBackoffMaxSecs),
+		"{{maxRestartsPerHour}}":       optionalInt(config.MaxRestartsPerHour),
 	}
 
 	for placeholder, value := range replacements {
@@ -82,31 +97,30 @@ func HandleConfigJSON(w http.ResponseWriter, r *http.Request) {
 
 func SaveConfigJSON(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
-		config := config.Config{
-			DiscordToken:            r.FormValue("discordToken"),
-			ControlChannelID:        r.FormValue("controlChannelID"),
-			StatusChannelID:         r.FormValue("statusChannelID"),
-			ConnectionListChannelID: r.FormValue("connectionListChannelID"),
-			LogChannelID:            r.FormValue("logChannelID"),
-			SaveChannelID:           r.FormValue("saveChannelID"),
-			ControlPanelChannelID:   r.FormValue("controlPanelChannelID"),
-			BlackListFilePath:       r.FormValue("blackListFilePath"),
-			ErrorChannelID:          r.FormValue("errorChannelID"), // New errorChannelID field
-			IsDiscordEnabled:        r.FormValue("isDiscordEnabled") == "true",
-		}
-
-		configPath := "./UIMod/config.json"
-		file, err := os.Create(configPath)
+		// Start from the existing file so settings that are not part of the form are kept
+		config, err := loadConfigJSON()
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Error creating config.json: %v", err), http.StatusInternalServerError)
+			http.Error(w, fmt.Sprintf("Error loading config.json: %v", err), http.StatusInternalServerError)
 			return
 		}
-		defer file.Close()
 
-		encoder := json.NewEncoder(file)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(&config); err != nil {
-			http.Error(w, fmt.Sprintf("Error encoding config.json: %v", err), http.StatusInternalServerError)
+		config.DiscordToken = r.FormValue("discordToken")
+		config.ControlChannelID = r.FormValue("controlChannelID")
+		config.StatusChannelID = r.FormValue("statusChannelID")
+		config.ConnectionListChannelID = r.FormValue("connectionListChannelID")
+		config.LogChannelID = r.FormValue("logChannelID")
+		config.SaveChannelID = r.FormValue("saveChannelID")
+		config.ControlPanelChannelID = r.FormValue("controlPanelChannelID")
+		config.BlackListFilePath = r.FormValue("blackListFilePath")
+		config.ErrorChannelID = r.FormValue("errorChannelID") // New errorChannelID field
+		config.IsDiscordEnabled = r.FormValue("isDiscordEnabled") == "true"
+		config.AutoRestartEnabled = r.FormValue("autoRestartEnabled") == "true"
+		config.RestartBackoffSeconds, _ = strconv.Atoi(r.FormValue("restartBackoffSeconds"))
+		config.RestartBackoffMaxSecs, _ = strconv.Atoi(r.FormValue("restartBackoffMaxSeconds"))
+		config.MaxRestartsPerHour, _ = strconv.Atoi(r.FormValue("maxRestartsPerHour"))
+
+		if err := saveConfigJSON(config); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
@@ -115,3 +129,28 @@ func SaveConfigJSON(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
 	}
 }
+
+// saveConfigJSON writes the controller config to the JSON file, changes apply on the next start
+func saveConfigJSON(cfg *config.Config) error {
+	configPath := "./UIMod/config.json"
+	file, err := os.Create(configPath)
+	if err != nil {
+		return fmt.Errorf("error creating config.json: %v", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(cfg); err != nil {
+		return fmt.Errorf("error encoding config.json: %v", err)
+	}
+	return nil
+}
+
+// optionalInt renders unset numeric settings as an empty field so the built-in default applies
+func optionalInt(value int) string {
+	if value <= 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
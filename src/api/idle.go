@@ -0,0 +1,150 @@
+package api
+
+import (
+	"StationeersServerUI/src/auth"
+	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/events"
+	"StationeersServerUI/src/lifecycle"
+	"StationeersServerUI/src/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const idleCheckInterval = 15 * time.Second
+
+var errNotSleeping = service.ErrNotSleeping
+
+var (
+	idleMu    sync.Mutex
+	idleSince time.Time // when the last player left a ready server, zero while players are on or the server is not ready
+	sleeping  bool      // the server was stopped by the idle policy and may be woken by anyone
+)
+
+// idleStatus is the idle timer shown in the status
+type idleStatus struct {
+	StopAfterMinutes int        `json:"stopAfterMinutes"`
+	IdleSince        *time.Time `json:"idleSince,omitempty"`
+	StopAt           *time.Time `json:"stopAt,omitempty"`
+	Sleeping         bool       `json:"sleeping"`
+}
+
+// StartIdleWatch stops the server after config.IdleStopMinutes without players, if set
+func StartIdleWatch() {
+	if config.IdleStopMinutes <= 0 {
+		return
+	}
+	fmt.Printf("Stopping the server after %d minutes without players\n", config.IdleStopMinutes)
+
+	sub := events.Subscribe("idle watch", 256)
+	go func() {
+		ticker := time.NewTicker(idleCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case e := <-sub.Events():
+				trackIdle(e)
+			case <-ticker.C:
+				stopIfIdle()
+			}
+		}
+	}()
+}
+
+// trackIdle starts the idle timer when a ready server has no players and clears it otherwise
+func trackIdle(e events.Event) {
+	idleMu.Lock()
+	defer idleMu.Unlock()
+
+	switch e.Type {
+	case events.ServerReady:
+		sleeping = false
+		if playerCount() == 0 {
+			idleSince = e.Time
+		}
+	case events.PlayerConnecting, events.PlayerReady:
+		idleSince = time.Time{}
+	case events.PlayerDisconnected:
+		if state, _ := lifecycle.Current(); state == lifecycle.Ready && playerCount() == 0 {
+			idleSince = e.Time
+		}
+	case events.ProcessExited:
+		idleSince = time.Time{}
+	}
+}
+
+// stopIfIdle stops the server once the idle timer has run out
+func stopIfIdle() {
+	idleMu.Lock()
+	since := idleSince
+	limit := time.Duration(config.IdleStopMinutes) * time.Minute
+	if since.IsZero() || time.Since(since) < limit || playerCount() > 0 {
+		idleMu.Unlock()
+		return
+	}
+	idleSince = time.Time{}
+	sleeping = true
+	idleMu.Unlock()
+
+	notifySupervisor(fmt.Sprintf("💤 No players for %d minutes, saving and stopping the server. Wake it with !wake or the Wake button.", config.IdleStopMinutes))
+	if _, err := (localServer{}).Stop(); err != nil {
+		idleMu.Lock()
+		sleeping = false
+		idleMu.Unlock()
+		notifySupervisor(fmt.Sprintf("❌ Stopping the idle server failed: %v", err))
+	}
+}
+
+// wakeServer starts a server that was stopped for being idle
+func wakeServer(requestedBy string) error {
+	idleMu.Lock()
+	if !sleeping {
+		idleMu.Unlock()
+		return errNotSleeping
+	}
+	sleeping = false
+	idleMu.Unlock()
+
+	if err := startServer(); err != nil {
+		idleMu.Lock()
+		sleeping = true
+		idleMu.Unlock()
+		return err
+	}
+	notifySupervisor(fmt.Sprintf("☀️ %s woke the server up.", requestedBy))
+	return nil
+}
+
+// currentIdleStatus returns the idle timer for the status, nil if the idle policy is off
+func currentIdleStatus() *idleStatus {
+	if config.IdleStopMinutes <= 0 {
+		return nil
+	}
+	idleMu.Lock()
+	defer idleMu.Unlock()
+
+	status := &idleStatus{StopAfterMinutes: config.IdleStopMinutes, Sleeping: sleeping}
+	if !idleSince.IsZero() {
+		since := idleSince
+		stopAt := idleSince.Add(time.Duration(config.IdleStopMinutes) * time.Minute)
+		status.IdleSince = &since
+		status.StopAt = &stopAt
+	}
+	return status
+}
+
+// WakeServer starts the server again after it was stopped for having no players
+func WakeServer(w http.ResponseWriter, r *http.Request) {
+	err := wakeServer(auth.Username(r))
+	var transitionErr *lifecycle.TransitionError
+	switch {
+	case errors.Is(err, errNotSleeping), errors.Is(err, errServerRunning), errors.As(err, &transitionErr):
+		writeJSONError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Error starting server: %v", err))
+	default:
+		writeJSON(w, http.StatusAccepted, currentStatus())
+	}
+}
@@ -0,0 +1,317 @@
+package api
+
+import (
+	"StationeersServerUI/src/config"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	logsDir          = "./logs"
+	sessionLogPrefix = "server-"
+	logTimeLayout    = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// sessionLog writes the server output of one server session to disk
+type sessionLog struct {
+	file     *os.File
+	session  string // start time of the session, shared by all parts
+	part     int
+	size     int64
+	openedAt time.Time
+}
+
+var (
+	sessionLogMu  sync.Mutex
+	currentLog    *sessionLog
+	compressingWg sync.WaitGroup
+)
+
+// openSessionLog starts a new log file for a server session, closing the log of the previous one
+func openSessionLog() {
+	sessionLogMu.Lock()
+	defer sessionLogMu.Unlock()
+
+	closeSessionLogLocked()
+	log, err := createLogPart(time.Now().Format("20060102-150405"), 1)
+	if err != nil {
+		fmt.Println("Error creating server log file:", err)
+		return
+	}
+	currentLog = log
+
+	go pruneOldLogs()
+}
+
+// closeSessionLog closes and compresses the log of the current server session
+func closeSessionLog() {
+	sessionLogMu.Lock()
+	defer sessionLogMu.Unlock()
+	closeSessionLogLocked()
+}
+
+func closeSessionLogLocked() {
+	if currentLog == nil {
+		return
+	}
+	path := currentLog.file.Name()
+	currentLog.file.Close()
+	currentLog = nil
+	compressLogInBackground(path)
+}
+
+func createLogPart(session string, part int) (*sessionLog, error) {
+	if err := os.MkdirAll(logsDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s%s.log", sessionLogPrefix, session)
+	if part > 1 {
+		name = fmt.Sprintf("%s%s-part%d.log", sessionLogPrefix, session, part)
+	}
+	file, err := os.OpenFile(filepath.Join(logsDir, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &sessionLog{file: file, session: session, part: part, openedAt: time.Now()}, nil
+}
+
+// writeSessionLog appends a timestamped line to the current session log, rotating it by size and age
+func writeSessionLog(line logLine) {
+	sessionLogMu.Lock()
+	defer sessionLogMu.Unlock()
+
+	if currentLog == nil {
+		return
+	}
+
+	maxSize := int64(config.LogMaxSizeMB) * 1024 * 1024
+	maxAge := time.Duration(config.LogMaxAgeHours) * time.Hour
+	if currentLog.size >= maxSize || time.Since(currentLog.openedAt) >= maxAge {
+		rotateSessionLogLocked()
+		if currentLog == nil {
+			return
+		}
+	}
+
+	n, err := fmt.Fprintf(currentLog.file, "%s %s\n", line.Time.Format(logTimeLayout), line.Text)
+	currentLog.size += int64(n)
+	if err != nil {
+		fmt.Println("Error writing server log file:", err)
+	}
+}
+
+// rotateSessionLogLocked continues the current session in a new part and compresses the full one
+func rotateSessionLogLocked() {
+	session, part := currentLog.session, currentLog.part
+	closeSessionLogLocked()
+
+	log, err := createLogPart(session, part+1)
+	if err != nil {
+		fmt.Println("Error rotating server log file:", err)
+		return
+	}
+	currentLog = log
+}
+
+func compressLogInBackground(path string) {
+	compressingWg.Add(1)
+	go func() {
+		defer compressingWg.Done()
+		if err := compressFile(path); err != nil {
+			fmt.Printf("Error compressing log file %s: %v\n", path, err)
+		}
+	}()
+}
+
+// compressFile gzips a file next to the original and removes the original
+func compressFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	defer destination.Close()
+
+	writer := gzip.NewWriter(destination)
+	writer.Name = filepath.Base(path)
+	if _, err := io.Copy(writer, source); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	if err := destination.Sync(); err != nil {
+		return err
+	}
+
+	source.Close()
+	return os.Remove(path)
+}
+
+// pruneOldLogs deletes server logs older than the retention period
+func pruneOldLogs() {
+	files, err := listLogFiles()
+	if err != nil {
+		return
+	}
+
+	retention := time.Duration(config.LogRetentionDays) * 24 * time.Hour
+	for _, file := range files {
+		if file.Current || time.Since(file.Modified) < retention {
+			continue
+		}
+		if err := os.Remove(filepath.Join(logsDir, file.Name)); err != nil {
+			fmt.Printf("Error removing old log file %s: %v\n", file.Name, err)
+		} else {
+			fmt.Println("Removed old log file:", file.Name)
+		}
+	}
+}
+
+type logFileInfo struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	Modified   time.Time `json:"modified"`
+	Compressed bool      `json:"compressed"`
+	Current    bool      `json:"current"`
+}
+
+// listLogFiles returns the server log files, newest first
+func listLogFiles() ([]logFileInfo, error) {
+	entries, err := os.ReadDir(logsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []logFileInfo{}, nil
+		}
+		return nil, err
+	}
+
+	currentName := ""
+	sessionLogMu.Lock()
+	if currentLog != nil {
+		currentName = filepath.Base(currentLog.file.Name())
+	}
+	sessionLogMu.Unlock()
+
+	files := []logFileInfo{}
+	for _, entry := range entries {
+		if entry.IsDir() || !isSessionLogName(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, logFileInfo{
+			Name:       entry.Name(),
+			Size:       info.Size(),
+			Modified:   info.ModTime(),
+			Compressed: strings.HasSuffix(entry.Name(), ".gz"),
+			Current:    entry.Name() == currentName,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Modified.After(files[j].Modified)
+	})
+	return files, nil
+}
+
+func isSessionLogName(name string) bool {
+	return strings.HasPrefix(name, sessionLogPrefix) && (strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log.gz"))
+}
+
+// parseSessionLogName returns the session and the part number from a log file name as written by createLogPart
+func parseSessionLogName(name string) (session string, part int) {
+	session = strings.TrimPrefix(name, sessionLogPrefix)
+	session = strings.TrimSuffix(strings.TrimSuffix(session, ".gz"), ".log")
+	if i := strings.LastIndex(session, "-part"); i >= 0 {
+		if n, err := strconv.Atoi(session[i+len("-part"):]); err == nil {
+			return session[:i], n
+		}
+	}
+	return session, 1
+}
+
+// sessionLogBefore orders log files by session, which is the start time, and then by part number
+func sessionLogBefore(a, b string) bool {
+	sessionA, partA := parseSessionLogName(a)
+	sessionB, partB := parseSessionLogName(b)
+	if sessionA != sessionB {
+		return sessionA < sessionB
+	}
+	return partA < partB
+}
+
+// ListLogs serves the list of server log files as JSON
+func ListLogs(w http.ResponseWriter, r *http.Request) {
+	files, err := listLogFiles()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error reading logs directory: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(files)
+}
+
+var (
+	errInvalidLogName = errors.New("invalid log file name")
+	errLogNotFound    = errors.New("log file not found")
+)
+
+// logFilePath validates a log file name from a request and returns its path
+func logFilePath(name string) (string, error) {
+	if name == "" || filepath.Base(name) != name || !isSessionLogName(name) {
+		return "", errInvalidLogName
+	}
+
+	path := filepath.Join(logsDir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", errLogNotFound
+	}
+	return path, nil
+}
+
+// serveLogDownload sends a log file as an attachment
+func serveLogDownload(w http.ResponseWriter, r *http.Request, path string) {
+	name := filepath.Base(path)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	if strings.HasSuffix(name, ".gz") {
+		w.Header().Set("Content-Type", "application/gzip")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	http.ServeFile(w, r, path)
+}
+
+// DownloadLog serves a single server log file as an attachment
+func DownloadLog(w http.ResponseWriter, r *http.Request) {
+	path, err := logFilePath(r.URL.Query().Get("name"))
+	if errors.Is(err, errInvalidLogName) {
+		http.Error(w, "Invalid log file name", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Log file not found", http.StatusNotFound)
+		return
+	}
+	serveLogDownload(w, r, path)
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// logLine is a single line of server output with its position in the output stream
+type logLine struct {
+	Seq  uint64
+	Time time.Time
+	Text string
+}
+
+// logHistory keeps the most recent output lines in a fixed size ring buffer
+type logHistory struct {
+	mu      sync.Mutex
+	lines   []logLine
+	start   int // index of the oldest line
+	count   int
+	nextSeq uint64
+}
+
+func newLogHistory(size int) *logHistory {
+	return &logHistory{lines: make([]logLine, size), nextSeq: 1}
+}
+
+// add stores a line, overwriting the oldest one when the buffer is full, and returns it with its sequence number
+func (h *logHistory) add(text string) logLine {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	line := logLine{Seq: h.nextSeq, Time: time.Now(), Text: text}
+	h.nextSeq++
+
+	if h.count < len(h.lines) {
+		h.lines[(h.start+h.count)%len(h.lines)] = line
+		h.count++
+	} else {
+		h.lines[h.start] = line
+		h.start = (h.start + 1) % len(h.lines)
+	}
+	return line
+}
+
+// last returns up to n of the most recent lines, oldest first
+func (h *logHistory) last(n int) []logLine {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lastLocked(n)
+}
+
+func (h *logHistory) lastLocked(n int) []logLine {
+	if n > h.count {
+		n = h.count
+	}
+	if n <= 0 {
+		return nil
+	}
+	result := make([]logLine, 0, n)
+	for i := h.count - n; i < h.count; i++ {
+		result = append(result, h.lines[(h.start+i)%len(h.lines)])
+	}
+	return result
+}
+
+// since returns the retained lines following the given sequence number, oldest first.
+// ok is false if seq does not belong to this stream, e.g. after the controller restarted.
+func (h *logHistory) since(seq uint64) (lines []logLine, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if seq >= h.nextSeq {
+		return nil, false
+	}
+	missed := h.nextSeq - 1 - seq
+	if missed > uint64(h.count) {
+		missed = uint64(h.count)
+	}
+	return h.lastLocked(int(missed)), true
+}
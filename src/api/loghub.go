@@ -0,0 +1,146 @@
+package api
+
+import (
+	"sync"
+)
+
+// overflowPolicy decides what happens when a subscriber's queue is full
+type overflowPolicy int
+
+const (
+	dropOldest     overflowPolicy = iota // discard the oldest queued line to make room
+	disconnectSlow                       // drop the subscriber, SSE clients reconnect and resume via Last-Event-ID
+)
+
+// logSubscriber receives output lines through its own buffered queue
+type logSubscriber struct {
+	lines   chan logLine // closed by the hub when the subscriber is removed
+	policy  overflowPolicy
+	dropped uint64 // guarded by the hub mutex
+}
+
+type hubStats struct {
+	Subscribers  int    `json:"subscribers"`
+	DroppedLines uint64 `json:"droppedLines"`
+	Disconnected uint64 `json:"disconnected"`
+}
+
+// logHub fans output lines out to subscribers without ever blocking the publisher,
+// so a stalled client cannot back up the pipe of the dedicated server
+type logHub struct {
+	mu           sync.Mutex
+	history      *logHistory
+	subscribers  map[*logSubscriber]struct{}
+	droppedLines uint64
+	disconnected uint64
+}
+
+func newLogHub(historySize int) *logHub {
+	return &logHub{
+		history:     newLogHistory(historySize),
+		subscribers: make(map[*logSubscriber]struct{}),
+	}
+}
+
+// publish records a line in the history and queues it for every subscriber
+func (h *logHub) publish(text string) logLine {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	line := h.history.add(text)
+	for sub := range h.subscribers {
+		h.offer(sub, line)
+	}
+	return line
+}
+
+// offer queues a line for one subscriber and applies its overflow policy if the queue is full, the caller must hold mu
+func (h *logHub) offer(sub *logSubscriber, line logLine) {
+	select {
+	case sub.lines <- line:
+		return
+	default:
+	}
+
+	switch sub.policy {
+	case dropOldest:
+		select {
+		case <-sub.lines:
+			sub.dropped++
+			h.droppedLines++
+		default:
+		}
+		select {
+		case sub.lines <- line:
+		default:
+			// The consumer cannot have refilled the queue, only the hub sends, but never block
+			sub.dropped++
+			h.droppedLines++
+		}
+	case disconnectSlow:
+		sub.dropped++
+		h.droppedLines++
+		h.disconnected++
+		h.removeLocked(sub)
+	}
+}
+
+// subscribe registers a subscriber with a queue of the given size. The replay function picks the history
+// lines to hand to the subscriber first; it runs under the hub lock so no line is missed or delivered twice.
+func (h *logHub) subscribe(queueSize int, policy overflowPolicy, replay func(history *logHistory) []logLine) (*logSubscriber, []logLine) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &logSubscriber{lines: make(chan logLine, queueSize), policy: policy}
+	h.subscribers[sub] = struct{}{}
+
+	var lines []logLine
+	if replay != nil {
+		lines = replay(h.history)
+	}
+	return sub, lines
+}
+
+// unsubscribe removes a subscriber, it is safe to call after the hub already disconnected it
+func (h *logHub) unsubscribe(sub *logSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(sub)
+}
+
+func (h *logHub) removeLocked(sub *logSubscriber) {
+	if _, ok := h.subscribers[sub]; !ok {
+		return
+	}
+	delete(h.subscribers, sub)
+	close(sub.lines)
+}
+
+func (h *logHub) stats() hubStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return hubStats{
+		Subscribers:  len(h.subscribers),
+		DroppedLines: h.droppedLines,
+		Disconnected: h.disconnected,
+	}
+}
+
+// SubscribeOutput hands every line of server output to handle in order, on a goroutine of its own.
+// It never disconnects, a handler that falls behind loses the oldest queued lines instead.
+// The returned function unsubscribes and waits until handle has seen the last queued line.
+func SubscribeOutput(handle func(line string)) (unsubscribe func()) {
+	sub, _ := outputHub.subscribe(outputQueueSize, dropOldest, nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for line := range sub.lines {
+			handle(line.Text)
+		}
+	}()
+	return func() {
+		outputHub.unsubscribe(sub)
+		<-done
+	}
+}
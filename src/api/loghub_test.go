@@ -0,0 +1,146 @@
+package api
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// drain returns the queued lines of a subscriber without waiting for more, and whether the hub closed its queue
+func drain(sub *logSubscriber) (lines []logLine, closed bool) {
+	for {
+		select {
+		case line, ok := <-sub.lines:
+			if !ok {
+				return lines, true
+			}
+			lines = append(lines, line)
+		default:
+			return lines, false
+		}
+	}
+}
+
+func texts(lines []logLine) []string {
+	list := []string{}
+	for _, line := range lines {
+		list = append(list, line.Text)
+	}
+	return list
+}
+
+func publishLines(hub *logHub, from, to int) {
+	for i := from; i < to; i++ {
+		hub.publish(strconv.Itoa(i))
+	}
+}
+
+func TestDropOldestKeepsNewestLines(t *testing.T) {
+	hub := newLogHub(100)
+	sub, _ := hub.subscribe(3, dropOldest, nil)
+
+	publishLines(hub, 0, 5)
+
+	lines, closed := drain(sub)
+	if closed {
+		t.Fatal("a dropOldest subscriber was disconnected")
+	}
+	if got, want := fmt.Sprint(texts(lines)), "[2 3 4]"; got != want {
+		t.Errorf("queued lines %s, want %s", got, want)
+	}
+	if sub.dropped != 2 {
+		t.Errorf("subscriber dropped %d lines, want 2", sub.dropped)
+	}
+	if stats := hub.stats(); stats != (hubStats{Subscribers: 1, DroppedLines: 2, Disconnected: 0}) {
+		t.Errorf("stats %+v", stats)
+	}
+}
+
+func TestDisconnectSlowRemovesOnlyTheSlowSubscriber(t *testing.T) {
+	hub := newLogHub(100)
+	slow, _ := hub.subscribe(2, disconnectSlow, nil)
+	fast, _ := hub.subscribe(10, disconnectSlow, nil)
+
+	publishLines(hub, 0, 3)
+
+	lines, closed := drain(slow)
+	if !closed {
+		t.Fatal("the slow subscriber is still connected")
+	}
+	if got, want := fmt.Sprint(texts(lines)), "[0 1]"; got != want {
+		t.Errorf("slow subscriber received %s, want %s", got, want)
+	}
+	lines, closed = drain(fast)
+	if closed {
+		t.Fatal("the fast subscriber was disconnected")
+	}
+	if got, want := fmt.Sprint(texts(lines)), "[0 1 2]"; got != want {
+		t.Errorf("fast subscriber received %s, want %s", got, want)
+	}
+	if stats := hub.stats(); stats != (hubStats{Subscribers: 1, DroppedLines: 1, Disconnected: 1}) {
+		t.Errorf("stats %+v", stats)
+	}
+}
+
+func TestUnsubscribeAfterDisconnect(t *testing.T) {
+	hub := newLogHub(100)
+	sub, _ := hub.subscribe(1, disconnectSlow, nil)
+	publishLines(hub, 0, 2)
+
+	// The handler unsubscribes on its way out after the hub already closed the queue
+	hub.unsubscribe(sub)
+	hub.unsubscribe(sub)
+
+	if stats := hub.stats(); stats != (hubStats{Subscribers: 0, DroppedLines: 1, Disconnected: 1}) {
+		t.Errorf("stats %+v", stats)
+	}
+	publishLines(hub, 2, 4) // must not send on the closed queue
+}
+
+func TestReconnectWithLastEventIDResumesWithoutGapOrDuplicate(t *testing.T) {
+	hub := newLogHub(100)
+	connect := func(lastEventID string) (*logSubscriber, []logLine) {
+		r := httptest.NewRequest("GET", "/output", nil)
+		if lastEventID != "" {
+			r.Header.Set("Last-Event-ID", lastEventID)
+		}
+		return hub.subscribe(4, disconnectSlow, func(history *logHistory) []logLine {
+			return replayLines(history, r)
+		})
+	}
+
+	publishLines(hub, 0, 3) // before the client connects, replayed as history
+	sub, replay := connect("")
+	received := append([]logLine{}, replay...)
+
+	// The client stalls, the hub disconnects it and more lines follow while it is away
+	publishLines(hub, 3, 10)
+	lines, closed := drain(sub)
+	if !closed {
+		t.Fatal("the stalled client is still connected")
+	}
+	received = append(received, lines...)
+	hub.unsubscribe(sub)
+	publishLines(hub, 10, 15)
+
+	// The browser reconnects with the id of the last event it received
+	lastID := strconv.FormatUint(received[len(received)-1].Seq, 10)
+	sub, replay = connect(lastID)
+	received = append(received, replay...)
+	publishLines(hub, 15, 18)
+	lines, closed = drain(sub)
+	if closed {
+		t.Fatal("the reconnected client was disconnected")
+	}
+	received = append(received, lines...)
+
+	if len(received) != 18 {
+		t.Fatalf("received %d lines, want 18: %v", len(received), texts(received))
+	}
+	for i, line := range received {
+		if line.Text != strconv.Itoa(i) || (i > 0 && line.Seq != received[i-1].Seq+1) {
+			t.Fatalf("line %d is %q with seq %d, want %q right after seq %d: %v", i, line.Text, line.Seq, strconv.Itoa(i), received[max(i-1, 0)].Seq, texts(received))
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+)
+
+// exceptionLinePattern matches the lines of an exception or stack trace in the server output
+var exceptionLinePattern = regexp.MustCompile(`(?m)^\s*>\s*\d{2}:\d{2}:\d{2}:.*Exception.*|>\s+\d{2}:\d{2}:\d{2}:.*StackTrace`)
+
+type logSearchQuery struct {
+	From      time.Time
+	To        time.Time
+	Substring string
+	Pattern   *regexp.Regexp
+	Severity  string // "exception", "normal" or empty for both
+	Offset    int
+	Limit     int
+}
+
+type logSearchResult struct {
+	Time     time.Time `json:"time"`
+	File     string    `json:"file"`
+	Line     string    `json:"line"`
+	Severity string    `json:"severity"`
+}
+
+type logSearchResponse struct {
+	Total   int               `json:"total"`
+	Offset  int               `json:"offset"`
+	Limit   int               `json:"limit"`
+	Results []logSearchResult `json:"results"`
+}
+
+func lineSeverity(text string) string {
+	if exceptionLinePattern.MatchString(text) {
+		return "exception"
+	}
+	return "normal"
+}
+
+// matches reports whether a log line passes all filters of the query
+func (q *logSearchQuery) matches(at time.Time, text, severity string) bool {
+	if !q.From.IsZero() && at.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && at.After(q.To) {
+		return false
+	}
+	if q.Severity != "" && q.Severity != severity {
+		return false
+	}
+	if q.Substring != "" && !strings.Contains(strings.ToLower(text), strings.ToLower(q.Substring)) {
+		return false
+	}
+	if q.Pattern != nil && !q.Pattern.MatchString(text) {
+		return false
+	}
+	return true
+}
+
+// searchLogs scans the retained log files in chronological order and returns one page of matching lines
+func searchLogs(q logSearchQuery) (logSearchResponse, error) {
+	response := logSearchResponse{Offset: q.Offset, Limit: q.Limit, Results: []logSearchResult{}}
+
+	files, err := listLogFiles()
+	if err != nil {
+		return response, err
+	}
+	// Names hold the session start time and the part number, which "-part10" or ".log.gz" would
+	// put out of order when compared as plain strings
+	sort.Slice(files, func(i, j int) bool {
+		return sessionLogBefore(files[i].Name, files[j].Name)
+	})
+
+	for _, file := range files {
+		// A file last written before the window cannot contain matching lines
+		if !q.From.IsZero() && file.Modified.Before(q.From) {
+			continue
+		}
+		if err := searchLogFile(file.Name, &q, &response); err != nil {
+			fmt.Printf("Error searching log file %s: %v\n", file.Name, err)
+		}
+	}
+	return response, nil
+}
+
+func searchLogFile(name string, q *logSearchQuery, response *logSearchResponse) error {
+	file, err := os.Open(filepath.Join(logsDir, name))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(name, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		stamp, text, found := strings.Cut(scanner.Text(), " ")
+		if !found {
+			continue
+		}
+		at, err := time.Parse(logTimeLayout, stamp)
+		if err != nil {
+			continue
+		}
+		if !q.To.IsZero() && at.After(q.To) {
+			// Lines are written in order, nothing later in this file can match
+			break
+		}
+
+		severity := lineSeverity(text)
+		if !q.matches(at, text, severity) {
+			continue
+		}
+
+		if response.Total >= q.Offset && len(response.Results) < q.Limit {
+			response.Results = append(response.Results, logSearchResult{Time: at, File: name, Line: text, Severity: severity})
+		}
+		response.Total++
+	}
+	return scanner.Err()
+}
+
+// parseSearchQuery reads the search filters from the query parameters of a request
+func parseSearchQuery(params url.Values) (logSearchQuery, error) {
+	q := logSearchQuery{
+		Substring: params.Get("q"),
+		Severity:  params.Get("severity"),
+		Limit:     defaultSearchLimit,
+	}
+
+	var err error
+	if from := params.Get("from"); from != "" {
+		if q.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return q, errors.New("invalid from parameter, expected RFC3339 time")
+		}
+	}
+	if to := params.Get("to"); to != "" {
+		if q.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return q, errors.New("invalid to parameter, expected RFC3339 time")
+		}
+	}
+	if pattern := params.Get("regex"); pattern != "" {
+		if q.Pattern, err = regexp.Compile(pattern); err != nil {
+			return q, fmt.Errorf("invalid regex: %v", err)
+		}
+	}
+	if q.Severity != "" && q.Severity != "exception" && q.Severity != "normal" {
+		return q, errors.New("invalid severity, expected exception or normal")
+	}
+	if offset := params.Get("offset"); offset != "" {
+		if q.Offset, err = strconv.Atoi(offset); err != nil || q.Offset < 0 {
+			return q, errors.New("invalid offset parameter")
+		}
+	}
+	if limit := params.Get("limit"); limit != "" {
+		if q.Limit, err = strconv.Atoi(limit); err != nil || q.Limit < 1 {
+			return q, errors.New("invalid limit parameter")
+		}
+		if q.Limit > maxSearchLimit {
+			q.Limit = maxSearchLimit
+		}
+	}
+	return q, nil
+}
+
+// SearchLogs searches the retained server output.
+// Parameters: from, to (RFC3339), q (substring), regex, severity (exception|normal), offset, limit.
+func SearchLogs(w http.ResponseWriter, r *http.Request) {
+	q, err := parseSearchQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := searchLogs(q)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error searching logs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
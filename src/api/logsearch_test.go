@@ -0,0 +1,112 @@
+package api
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// inTempDir runs the test in an empty working directory, the logs live in ./logs
+func inTempDir(t *testing.T) {
+	t.Helper()
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(previous) })
+}
+
+// writeLogFile writes a log file with one timestamped line, gzipped if the name ends in .gz
+func writeLogFile(t *testing.T, name string, at time.Time, text string) {
+	t.Helper()
+	if err := os.MkdirAll(logsDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(filepath.Join(logsDir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	content := at.Format(logTimeLayout) + " " + text + "\n"
+	if filepath.Ext(name) == ".gz" {
+		writer := gzip.NewWriter(file)
+		writer.Write([]byte(content))
+		writer.Close()
+		return
+	}
+	file.WriteString(content)
+}
+
+func TestParseSessionLogName(t *testing.T) {
+	tests := []struct {
+		name    string
+		session string
+		part    int
+	}{
+		{"server-20260101-100000.log", "20260101-100000", 1},
+		{"server-20260101-100000.log.gz", "20260101-100000", 1},
+		{"server-20260101-100000-part2.log", "20260101-100000", 2},
+		{"server-20260101-100000-part10.log.gz", "20260101-100000", 10},
+	}
+	for _, test := range tests {
+		session, part := parseSessionLogName(test.name)
+		if session != test.session || part != test.part {
+			t.Errorf("parseSessionLogName(%q) = %q, %d, want %q, %d", test.name, session, part, test.session, test.part)
+		}
+	}
+}
+
+func TestSearchLogsOrdersRotatedParts(t *testing.T) {
+	inTempDir(t)
+
+	// An earlier session and a session rotated into 11 parts, the first ones compressed
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	var want []time.Time
+	writePart := func(name string, at time.Time) {
+		writeLogFile(t, name, at, fmt.Sprintf("line %d", len(want)))
+		want = append(want, at)
+	}
+	writePart("server-20251231-090000.log.gz", start.Add(-25*time.Hour))
+	writePart("server-20260101-100000.log.gz", start)
+	for part := 2; part <= 11; part++ {
+		name := fmt.Sprintf("server-20260101-100000-part%d.log", part)
+		if part < 11 {
+			name += ".gz"
+		}
+		writePart(name, start.Add(time.Duration(part)*time.Minute))
+	}
+
+	response, err := searchLogs(logSearchQuery{Limit: maxSearchLimit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Total != len(want) || len(response.Results) != len(want) {
+		t.Fatalf("got %d results of %d, want %d", len(response.Results), response.Total, len(want))
+	}
+	for i, result := range response.Results {
+		if !result.Time.Equal(want[i]) {
+			t.Errorf("result %d is %s from %s, want %s", i, result.Time, result.File, want[i])
+		}
+	}
+
+	// Paging continues where the previous page stopped
+	page, err := searchLogs(logSearchQuery{Offset: 4, Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != len(want) || len(page.Results) != 3 {
+		t.Fatalf("got %d results of %d, want 3 of %d", len(page.Results), page.Total, len(want))
+	}
+	for i, result := range page.Results {
+		if !result.Time.Equal(want[4+i]) {
+			t.Errorf("page result %d is %s from %s, want %s", i, result.Time, result.File, want[4+i])
+		}
+	}
+}
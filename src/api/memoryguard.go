@@ -0,0 +1,115 @@
+package api
+
+import (
+	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/discord"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	memoryCurveStep  = 5 * time.Minute // one row of the memory curve per step
+	memoryCurveWidth = 30              // characters of the longest bar
+)
+
+// State of the memory guard, only used by the resource monitor goroutine
+var (
+	memoryWatchPID   int       // the process the state below belongs to
+	memoryAboveSince time.Time // when the server went above the limit, zero while below
+	memoryRestarted  bool      // a countdown for this process is running, cleared when it is gone
+)
+
+// checkMemoryLimit restarts the server with warnings once it stayed above config.MemoryLimitMB for config.MemoryLimitMinutes
+func checkMemoryLimit(sample resourceSample) {
+	if config.MemoryLimitMB <= 0 {
+		return
+	}
+	if sample.PID != memoryWatchPID {
+		memoryWatchPID = sample.PID
+		memoryAboveSince = time.Time{}
+		memoryRestarted = false
+	}
+
+	limit := uint64(config.MemoryLimitMB) * 1024 * 1024
+	if sample.RSSBytes < limit {
+		if !memoryAboveSince.IsZero() {
+			fmt.Printf("Server memory back below %d MB\n", config.MemoryLimitMB)
+		}
+		memoryAboveSince = time.Time{}
+		return
+	}
+	if memoryRestarted && currentCountdown() == nil {
+		// The countdown was cancelled, wait for another full period before asking again
+		memoryRestarted = false
+		memoryAboveSince = sample.Time
+	}
+	if memoryAboveSince.IsZero() {
+		memoryAboveSince = sample.Time
+		fmt.Printf("⚠️Server memory above %d MB, restarting if it stays there for %d minutes\n", config.MemoryLimitMB, config.MemoryLimitMinutes)
+	}
+	if memoryRestarted || sample.Time.Sub(memoryAboveSince) < time.Duration(config.MemoryLimitMinutes)*time.Minute {
+		return
+	}
+
+	reason := fmt.Sprintf("memory at %d MB, above the limit of %d MB for %d minutes", sample.RSSBytes/(1024*1024), config.MemoryLimitMB, config.MemoryLimitMinutes)
+	_, err := startCountdownRestart(reason, "Memory guard")
+	switch {
+	case errors.Is(err, errCountdownActive):
+		memoryRestarted = true
+		go notifySupervisor(fmt.Sprintf("🧠 Server %s, a restart is already counting down.", reason))
+		return
+	case err != nil:
+		memoryAboveSince = sample.Time
+		go notifySupervisor(fmt.Sprintf("❌ Memory guard could not restart the server: %v", err))
+		return
+	}
+	memoryRestarted = true
+
+	var samples []resourceSample
+	for _, s := range resourceSamplesSince(time.Time{}) {
+		if s.PID == sample.PID {
+			samples = append(samples, s)
+		}
+	}
+	curve := memoryCurve(samples)
+	fmt.Print(curve)
+	if config.IsDiscordEnabled {
+		// Not on the sampler goroutine, Discord may be slow to answer
+		go discord.SendMessageToStatusChannel("🧠 Server memory over the last hour:\n```\n" + curve + "```")
+	}
+}
+
+// memoryCurve draws the memory of the samples as one bar per step, the latest sample is always the last row
+func memoryCurve(samples []resourceSample) string {
+	if len(samples) == 0 {
+		return ""
+	}
+
+	var rows []resourceSample
+	var next time.Time
+	for i, sample := range samples {
+		if !sample.Time.Before(next) || i == len(samples)-1 {
+			rows = append(rows, sample)
+			next = sample.Time.Add(memoryCurveStep)
+		}
+	}
+
+	var max uint64
+	for _, row := range rows {
+		if row.RSSBytes > max {
+			max = row.RSSBytes
+		}
+	}
+
+	var curve strings.Builder
+	for _, row := range rows {
+		bar := int(row.RSSBytes * memoryCurveWidth / max)
+		if bar < 1 {
+			bar = 1
+		}
+		fmt.Fprintf(&curve, "%s %6d MB %s\n", row.Time.Format("15:04"), row.RSSBytes/(1024*1024), strings.Repeat("█", bar))
+	}
+	return curve.String()
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/discord"
+	"StationeersServerUI/src/lifecycle"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Counters for the metrics, since the controller started
+var (
+	requestedRestarts   atomic.Uint64 // restarts asked for by users, schedules, rules, policies and guards
+	automaticRestarts   atomic.Uint64 // restarts by the supervisor after a crash
+	crashes             atomic.Uint64
+	backupFilesCopied   atomic.Uint64
+	backupFilesPruned   atomic.Uint64 // from the backup folder
+	safebackupsPruned   atomic.Uint64 // from Safebackups
+	sseClientsMu        sync.Mutex
+	sseClients          = map[string]int{"output": 0, "events": 0, "resources": 0}
+	labelValueEscaper   = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	safebackupsSizeMu   sync.Mutex
+	safebackupsSize     int64
+	safebackupsSizeTime time.Time
+)
+
+// safebackupsSizeMaxAge keeps frequent scrapes from walking the Safebackups folder every time
+const safebackupsSizeMaxAge = time.Minute
+
+// trackSSEClient counts a connected client of an SSE stream until the returned function is called
+func trackSSEClient(stream string) func() {
+	sseClientsMu.Lock()
+	sseClients[stream]++
+	sseClientsMu.Unlock()
+	return func() {
+		sseClientsMu.Lock()
+		sseClients[stream]--
+		sseClientsMu.Unlock()
+	}
+}
+
+// metricsWriter writes the Prometheus text format
+type metricsWriter struct {
+	w io.Writer
+}
+
+func (m metricsWriter) metric(name, kind, help string) {
+	fmt.Fprintf(m.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// value writes a sample, labels alternate between names and values
+func (m metricsWriter) value(name string, v float64, labels ...string) {
+	var pairs []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], labelValueEscaper.Replace(labels[i+1])))
+	}
+	if len(pairs) > 0 {
+		name += "{" + strings.Join(pairs, ",") + "}"
+	}
+	fmt.Fprintf(m.w, "%s %s\n", name, strconv.FormatFloat(v, 'f', -1, 64))
+}
+
+// Metrics serves the state of the server and the controller in the Prometheus text format
+func Metrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m := metricsWriter{w}
+	status := currentStatus()
+
+	m.metric("ssui_build_info", "gauge", "Version and branch of the controller.")
+	m.value("ssui_build_info", 1, "version", config.Version, "branch", config.Branch)
+
+	m.metric("ssui_server_state", "gauge", "Lifecycle state of the dedicated server, 1 for the current state.")
+	for _, state := range lifecycle.States {
+		m.value("ssui_server_state", boolValue(status.State == state), "state", string(state))
+	}
+
+	m.metric("ssui_server_uptime_seconds", "gauge", "Time since the running server process started, 0 if it is not running.")
+	m.value("ssui_server_uptime_seconds", float64(status.UptimeSeconds))
+
+	m.metric("ssui_players_connected", "gauge", "Players connected to the server.")
+	m.value("ssui_players_connected", float64(status.PlayerCount))
+
+	m.metric("ssui_server_restart_pending", "gauge", "1 while an automatic restart after a crash or a restart countdown is pending.")
+	m.value("ssui_server_restart_pending", boolValue(status.RestartPending || status.Countdown != nil))
+
+	m.metric("ssui_server_restarts_total", "counter", "Server restarts, by what caused them.")
+	m.value("ssui_server_restarts_total", float64(requestedRestarts.Load()), "trigger", "requested")
+	m.value("ssui_server_restarts_total", float64(automaticRestarts.Load()), "trigger", "crash")
+
+	m.metric("ssui_server_crashes_total", "counter", "Server processes that exited without being asked to.")
+	m.value("ssui_server_crashes_total", float64(crashes.Load()))
+
+	m.metric("ssui_exceptions_total", "counter", "Server exceptions by stack signature, the least recently seen signatures are dropped beyond 500.")
+	for _, group := range listExceptions() {
+		m.value("ssui_exceptions_total", float64(group.Count), "signature", group.Signature, "type", group.Type)
+	}
+
+	m.metric("ssui_backup_files_copied_total", "counter", "Backup files copied to Safebackups.")
+	m.value("ssui_backup_files_copied_total", float64(backupFilesCopied.Load()))
+
+	m.metric("ssui_backup_files_pruned_total", "counter", "Backup files deleted by the backup cleanup, by folder.")
+	m.value("ssui_backup_files_pruned_total", float64(backupFilesPruned.Load()), "folder", "backup")
+	m.value("ssui_backup_files_pruned_total", float64(safebackupsPruned.Load()), "folder", "Safebackups")
+
+	if size, err := safebackupsDiskUsage(); err == nil {
+		m.metric("ssui_safebackups_bytes", "gauge", "Disk space used by the Safebackups folder of the current world.")
+		m.value("ssui_safebackups_bytes", float64(size))
+	}
+
+	m.metric("ssui_sse_clients", "gauge", "Connected clients of the server-sent event streams.")
+	sseClientsMu.Lock()
+	for _, stream := range []string{"output", "events", "resources"} {
+		m.value("ssui_sse_clients", float64(sseClients[stream]), "stream", stream)
+	}
+	sseClientsMu.Unlock()
+
+	m.metric("ssui_output_lines_dropped_total", "counter", "Output lines skipped for output clients that fell behind.")
+	m.value("ssui_output_lines_dropped_total", float64(status.Output.DroppedLines))
+
+	m.metric("ssui_discord_send_errors_total", "counter", "Discord API requests that failed, rate limited requests retried by the Discord library are not counted.")
+	m.value("ssui_discord_send_errors_total", float64(discord.SendErrors()))
+
+	// The latest resource sample, if it belongs to the running server
+	if samples := resourceSamplesSince(time.Now().Add(-2 * resourceSampleInterval)); len(samples) > 0 && samples[len(samples)-1].PID == status.PID {
+		latest := samples[len(samples)-1]
+		m.metric("ssui_server_cpu_percent", "gauge", "CPU used by the server during the last sample interval, in percent of one core.")
+		m.value("ssui_server_cpu_percent", latest.CPUPercent)
+		m.metric("ssui_server_memory_bytes", "gauge", "Resident memory of the server.")
+		m.value("ssui_server_memory_bytes", float64(latest.RSSBytes))
+		m.metric("ssui_server_threads", "gauge", "Threads of the server process.")
+		m.value("ssui_server_threads", float64(latest.Threads))
+		m.metric("ssui_server_open_files", "gauge", "Open file descriptors of the server process.")
+		m.value("ssui_server_open_files", float64(latest.OpenFiles))
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// safebackupsDiskUsage adds up the files in the Safebackups folder of the current world, at most once a minute
+func safebackupsDiskUsage() (int64, error) {
+	safebackupsSizeMu.Lock()
+	defer safebackupsSizeMu.Unlock()
+	if time.Since(safebackupsSizeTime) < safebackupsSizeMaxAge {
+		return safebackupsSize, nil
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	err = filepath.WalkDir("./saves/"+config.SaveFileName+"/Safebackups", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	safebackupsSize, safebackupsSizeTime = size, time.Now()
+	return size, nil
+}
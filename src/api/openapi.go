@@ -0,0 +1,629 @@
+package api
+
+import (
+	"StationeersServerUI/src/config"
+	"net/http"
+	"strings"
+)
+
+// ServeOpenAPI serves the OpenAPI document of the /api/v1 endpoints
+func ServeOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(strings.Replace(openAPISpec, "{{Version}}", config.Version, 1)))
+}
+
+const openAPISpec = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "Stationeers Server UI API",
+    "version": "{{Version}}",
+    "description": "Control and monitor a Stationeers dedicated server. Authenticate with the session cookie from /login or with an API token as 'Authorization: Bearer ssui_...'. Errors are returned as {\"error\": \"...\"}."
+  },
+  "servers": [{ "url": "/api/v1" }],
+  "security": [{ "bearerToken": [] }, { "sessionCookie": [] }],
+  "paths": {
+    "/status": {
+      "get": {
+        "summary": "Lifecycle state, process details and player count",
+        "tags": ["status"],
+        "responses": {
+          "200": { "description": "Current status", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Status" } } } }
+        }
+      }
+    },
+    "/server/start": {
+      "post": {
+        "summary": "Start the dedicated server",
+        "description": "Answers once the process is launched, poll /status until the state is Ready. Requires process:control.",
+        "tags": ["server"],
+        "responses": {
+          "202": { "description": "Server is starting", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Status" } } } },
+          "409": { "$ref": "#/components/responses/Conflict" },
+          "500": { "$ref": "#/components/responses/Error" }
+        }
+      }
+    },
+    "/server/stop": {
+      "post": {
+        "summary": "Save and stop the dedicated server",
+        "description": "Sends the save and quit commands and escalates to SIGTERM and SIGKILL. Requires process:control.",
+        "tags": ["server"],
+        "responses": {
+          "200": { "description": "Server stopped", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Message" } } } },
+          "409": { "$ref": "#/components/responses/Conflict" },
+          "500": { "$ref": "#/components/responses/Error" }
+        }
+      }
+    },
+    "/server/restart": {
+      "post": {
+        "summary": "Stop the dedicated server if it is running and start it again",
+        "tags": ["server"],
+        "responses": {
+          "202": { "description": "Server is starting", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Message" } } } },
+          "500": { "$ref": "#/components/responses/Error" }
+        }
+      }
+    },
+    "/server/wake": {
+      "post": {
+        "summary": "Start the server again after it was stopped for having no players, allowed for every role",
+        "tags": ["server"],
+        "responses": {
+          "202": { "description": "Server is starting", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Status" } } } },
+          "409": { "$ref": "#/components/responses/Conflict" },
+          "500": { "$ref": "#/components/responses/Error" }
+        }
+      }
+    },
+    "/server/restart/countdown": {
+      "post": {
+        "summary": "Warn the players in game and restart the server after the longest warning",
+        "tags": ["server"],
+        "parameters": [{ "name": "reason", "in": "query", "description": "Shown in the notifications", "schema": { "type": "string" } }],
+        "responses": {
+          "202": { "description": "Countdown started, the restart time is in the message and in the status", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Message" } } } },
+          "409": { "$ref": "#/components/responses/Conflict" }
+        }
+      },
+      "delete": {
+        "summary": "Cancel the restart countdown, possible until the final warning",
+        "tags": ["server"],
+        "responses": {
+          "200": { "description": "Cancelled", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Message" } } } },
+          "404": { "$ref": "#/components/responses/NotFound" },
+          "409": { "$ref": "#/components/responses/Conflict" }
+        }
+      }
+    },
+    "/console": {
+      "post": {
+        "summary": "Send an allowlisted console command",
+        "tags": ["server"],
+        "requestBody": {
+          "required": true,
+          "content": { "application/json": { "schema": { "type": "object", "required": ["command"], "properties": { "command": { "type": "string", "example": "say Hello" } } } } }
+        },
+        "responses": {
+          "202": { "description": "Command sent", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Message" } } } },
+          "400": { "$ref": "#/components/responses/BadRequest" },
+          "403": { "$ref": "#/components/responses/Forbidden" },
+          "409": { "$ref": "#/components/responses/Conflict" }
+        }
+      }
+    },
+    "/console/audit": {
+      "get": {
+        "summary": "Most recent console commands and who sent them",
+        "tags": ["server"],
+        "responses": {
+          "200": { "description": "Audit entries", "content": { "application/json": { "schema": { "type": "array", "items": { "$ref": "#/components/schemas/AuditEntry" } } } } }
+        }
+      }
+    },
+    "/backups": {
+      "get": {
+        "summary": "Restorable backups, newest first",
+        "tags": ["backups"],
+        "responses": {
+          "200": { "description": "Backups", "content": { "application/json": { "schema": { "type": "array", "items": { "$ref": "#/components/schemas/Backup" } } } } },
+          "500": { "$ref": "#/components/responses/Error" }
+        }
+      }
+    },
+    "/backups/{index}/restore": {
+      "post": {
+        "summary": "Restore a backup over the current save",
+        "description": "The server must be stopped. Requires backups:manage.",
+        "tags": ["backups"],
+        "parameters": [{ "name": "index", "in": "path", "required": true, "schema": { "type": "integer", "minimum": 0 } }],
+        "responses": {
+          "200": { "description": "Backup restored", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Message" } } } },
+          "400": { "$ref": "#/components/responses/BadRequest" },
+          "404": { "$ref": "#/components/responses/NotFound" },
+          "409": { "$ref": "#/components/responses/Conflict" }
+        }
+      }
+    },
+    "/config": {
+      "get": {
+        "summary": "Game and controller configuration, secrets are redacted",
+        "description": "Requires config:manage.",
+        "tags": ["config"],
+        "responses": {
+          "200": { "description": "Configuration", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Config" } } } }
+        }
+      }
+    },
+    "/config/game": {
+      "patch": {
+        "summary": "Change the save name and launch settings of the game server",
+        "description": "Only the given settings change, an empty value removes a setting. Applies on the next server start.",
+        "tags": ["config"],
+        "requestBody": {
+          "required": true,
+          "content": { "application/json": { "schema": { "type": "object", "properties": {
+            "saveFileName": { "type": "string" },
+            "settings": { "type": "object", "additionalProperties": { "type": "string" }, "example": { "ServerName": "My Station", "ServerMaxPlayers": "8" } }
+          } } } }
+        },
+        "responses": {
+          "200": { "description": "Updated configuration", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Config" } } } },
+          "400": { "$ref": "#/components/responses/BadRequest" }
+        }
+      }
+    },
+    "/config/controller": {
+      "patch": {
+        "summary": "Change fields of the controller configuration (config.json)",
+        "description": "Only the fields in the body change. Sending the redacted Discord token keeps the stored one. Applies on the next controller start.",
+        "tags": ["config"],
+        "requestBody": {
+          "required": true,
+          "content": { "application/json": { "schema": { "type": "object", "additionalProperties": true, "example": { "autoRestartEnabled": true, "maxRestartsPerHour": 3 } } } }
+        },
+        "responses": {
+          "200": { "description": "Updated configuration", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Config" } } } },
+          "400": { "$ref": "#/components/responses/BadRequest" }
+        }
+      }
+    },
+    "/events": {
+      "get": {
+        "summary": "Stream of server events",
+        "description": "Server-sent events, the event name is the event type and the data is the Event as JSON. Replays the last 100 events on connect.",
+        "tags": ["status"],
+        "parameters": [{ "name": "replay", "in": "query", "description": "How many recent events to replay", "schema": { "type": "integer", "minimum": 0 } }],
+        "responses": {
+          "200": { "description": "Event stream", "content": { "text/event-stream": { "schema": { "$ref": "#/components/schemas/Event" } } } }
+        }
+      }
+    },
+    "/resources": {
+      "get": {
+        "summary": "Resource usage of the server from /proc, sampled every 5 seconds, oldest first",
+        "description": "The last hour is kept. Only available on Linux, available is false elsewhere.",
+        "tags": ["status"],
+        "parameters": [{ "name": "minutes", "in": "query", "description": "Only samples from the last N minutes", "schema": { "type": "integer", "minimum": 1 } }],
+        "responses": {
+          "200": { "description": "Resource history", "content": { "application/json": { "schema": {
+            "type": "object",
+            "properties": {
+              "available": { "type": "boolean" },
+              "intervalSeconds": { "type": "integer" },
+              "samples": { "type": "array", "items": { "$ref": "#/components/schemas/ResourceSample" } }
+            }
+          } } } }
+        }
+      }
+    },
+    "/resources/stream": {
+      "get": {
+        "summary": "Stream of resource samples",
+        "description": "Server-sent events, the data of every message is a new ResourceSample as JSON.",
+        "tags": ["status"],
+        "responses": {
+          "200": { "description": "Sample stream", "content": { "text/event-stream": { "schema": { "$ref": "#/components/schemas/ResourceSample" } } } }
+        }
+      }
+    },
+    "/exceptions": {
+      "get": {
+        "summary": "Server exceptions grouped by normalised stack signature, most recently seen first",
+        "tags": ["status"],
+        "responses": {
+          "200": { "description": "Exception groups", "content": { "application/json": { "schema": { "type": "array", "items": { "$ref": "#/components/schemas/ExceptionGroup" } } } } }
+        }
+      }
+    },
+    "/policies": {
+      "get": {
+        "summary": "Exception policies and the automatic actions they took, newest first",
+        "tags": ["status"],
+        "responses": {
+          "200": { "description": "Policies and actions", "content": { "application/json": { "schema": { "type": "object", "properties": {
+            "policies": { "type": "array", "items": { "type": "object", "properties": {
+              "name": { "type": "string" },
+              "signatures": { "type": "array", "items": { "type": "string" } },
+              "pattern": { "type": "string" },
+              "threshold": { "type": "integer" },
+              "windowSeconds": { "type": "integer" },
+              "action": { "type": "string", "enum": ["restart", "stop"] },
+              "cooldownSeconds": { "type": "integer" },
+              "occurrences": { "type": "integer" },
+              "lastAction": { "type": "string", "format": "date-time" }
+            } } },
+            "actions": { "type": "array", "items": { "type": "object", "properties": {
+              "time": { "type": "string", "format": "date-time" },
+              "policy": { "type": "string" },
+              "action": { "type": "string" },
+              "result": { "type": "string" },
+              "signature": { "type": "string" },
+              "threshold": { "type": "integer" },
+              "window": { "type": "string" },
+              "occurrences": { "type": "array", "items": { "type": "string", "format": "date-time" } },
+              "trace": { "type": "string" }
+            } } }
+          } } } } }
+        }
+      }
+    },
+    "/rules": {
+      "get": {
+        "summary": "Active log rules",
+        "tags": ["rules"],
+        "responses": {
+          "200": { "description": "Rules", "content": { "application/json": { "schema": { "type": "object", "properties": {
+            "file": { "type": "string" },
+            "loadedAt": { "type": "string", "format": "date-time" },
+            "error": { "type": "string", "description": "Why the rules file on disk is not active" },
+            "rules": { "type": "array", "items": { "$ref": "#/components/schemas/Rule" } }
+          } } } } }
+        }
+      }
+    },
+    "/rules/test": {
+      "post": {
+        "summary": "Run a log line against every rule without carrying out the actions",
+        "tags": ["rules"],
+        "requestBody": {
+          "required": true,
+          "content": { "application/json": { "schema": { "type": "object", "required": ["line"], "properties": { "line": { "type": "string", "example": "Client Bob (76561198000000001) is ready!" } } } } }
+        },
+        "responses": {
+          "200": { "description": "Matching rules", "content": { "application/json": { "schema": { "type": "object", "properties": {
+            "line": { "type": "string" },
+            "matches": { "type": "array", "items": { "$ref": "#/components/schemas/RuleMatch" } }
+          } } } } },
+          "400": { "$ref": "#/components/responses/BadRequest" }
+        }
+      }
+    },
+    "/players": {
+      "get": {
+        "summary": "Players connected right now",
+        "tags": ["players"],
+        "responses": {
+          "200": { "description": "Players", "content": { "application/json": { "schema": { "type": "array", "items": { "$ref": "#/components/schemas/Player" } } } } }
+        }
+      }
+    },
+    "/players/bans": {
+      "get": {
+        "summary": "Banned SteamIDs",
+        "tags": ["players"],
+        "responses": {
+          "200": { "description": "SteamIDs", "content": { "application/json": { "schema": { "type": "array", "items": { "type": "string" } } } } }
+        }
+      }
+    },
+    "/players/bans/{steamId}": {
+      "parameters": [{ "name": "steamId", "in": "path", "required": true, "schema": { "type": "string", "pattern": "^[0-9]+$" } }],
+      "put": {
+        "summary": "Ban a player",
+        "tags": ["players"],
+        "responses": {
+          "200": { "description": "Already banned", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Message" } } } },
+          "201": { "description": "Banned", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Message" } } } },
+          "400": { "$ref": "#/components/responses/BadRequest" }
+        }
+      },
+      "delete": {
+        "summary": "Unban a player",
+        "tags": ["players"],
+        "responses": {
+          "204": { "description": "Unbanned" },
+          "404": { "$ref": "#/components/responses/NotFound" }
+        }
+      }
+    },
+    "/logs": {
+      "get": {
+        "summary": "Server log files, one per session, newest first",
+        "tags": ["logs"],
+        "responses": {
+          "200": { "description": "Log files", "content": { "application/json": { "schema": { "type": "array", "items": { "$ref": "#/components/schemas/LogFile" } } } } }
+        }
+      }
+    },
+    "/logs/search": {
+      "get": {
+        "summary": "Search the server logs",
+        "tags": ["logs"],
+        "parameters": [
+          { "name": "from", "in": "query", "schema": { "type": "string", "format": "date-time" } },
+          { "name": "to", "in": "query", "schema": { "type": "string", "format": "date-time" } },
+          { "name": "q", "in": "query", "description": "Case-insensitive text", "schema": { "type": "string" } },
+          { "name": "regex", "in": "query", "schema": { "type": "string" } },
+          { "name": "severity", "in": "query", "schema": { "type": "string", "enum": ["exception", "normal"] } },
+          { "name": "offset", "in": "query", "schema": { "type": "integer", "minimum": 0 } },
+          { "name": "limit", "in": "query", "schema": { "type": "integer", "minimum": 1, "maximum": 1000, "default": 100 } }
+        ],
+        "responses": {
+          "200": { "description": "One page of matching lines", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/LogSearch" } } } },
+          "400": { "$ref": "#/components/responses/BadRequest" }
+        }
+      }
+    },
+    "/logs/{name}": {
+      "get": {
+        "summary": "Download a log file",
+        "tags": ["logs"],
+        "parameters": [{ "name": "name", "in": "path", "required": true, "schema": { "type": "string" } }],
+        "responses": {
+          "200": { "description": "The log file, gzipped if it was rotated", "content": { "text/plain": {}, "application/gzip": {} } },
+          "400": { "$ref": "#/components/responses/BadRequest" },
+          "404": { "$ref": "#/components/responses/NotFound" }
+        }
+      }
+    },
+    "/schedules": {
+      "get": {
+        "summary": "Scheduled tasks with their next 5 runs",
+        "tags": ["schedules"],
+        "responses": {
+          "200": { "description": "Schedules", "content": { "application/json": { "schema": { "type": "array", "items": { "$ref": "#/components/schemas/Schedule" } } } } }
+        }
+      },
+      "post": {
+        "summary": "Add a scheduled task",
+        "tags": ["schedules"],
+        "requestBody": { "required": true, "content": { "application/json": { "schema": { "$ref": "#/components/schemas/ScheduleInput" } } } },
+        "responses": {
+          "201": { "description": "The new schedule", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Schedule" } } } },
+          "400": { "$ref": "#/components/responses/BadRequest" }
+        }
+      }
+    },
+    "/schedules/{id}": {
+      "parameters": [{ "name": "id", "in": "path", "required": true, "schema": { "type": "string" } }],
+      "put": {
+        "summary": "Change a scheduled task, its last run and skip flag are kept",
+        "tags": ["schedules"],
+        "requestBody": { "required": true, "content": { "application/json": { "schema": { "$ref": "#/components/schemas/ScheduleInput" } } } },
+        "responses": {
+          "200": { "description": "The changed schedule", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Schedule" } } } },
+          "400": { "$ref": "#/components/responses/BadRequest" },
+          "404": { "$ref": "#/components/responses/NotFound" }
+        }
+      },
+      "delete": {
+        "summary": "Remove a scheduled task",
+        "tags": ["schedules"],
+        "responses": {
+          "200": { "description": "Removed", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Message" } } } },
+          "404": { "$ref": "#/components/responses/NotFound" }
+        }
+      }
+    },
+    "/schedules/{id}/skip": {
+      "post": {
+        "summary": "Skip the next run of a scheduled task",
+        "tags": ["schedules"],
+        "parameters": [
+          { "name": "id", "in": "path", "required": true, "schema": { "type": "string" } },
+          { "name": "skip", "in": "query", "description": "false runs the next occurrence again", "schema": { "type": "boolean", "default": true } }
+        ],
+        "responses": {
+          "200": { "description": "The schedule", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Schedule" } } } },
+          "404": { "$ref": "#/components/responses/NotFound" }
+        }
+      }
+    },
+    "/schedules/history": {
+      "get": {
+        "summary": "Runs of the scheduled tasks, newest first",
+        "tags": ["schedules"],
+        "responses": {
+          "200": { "description": "Runs", "content": { "application/json": { "schema": { "type": "array", "items": { "$ref": "#/components/schemas/ScheduleRun" } } } } }
+        }
+      }
+    },
+    "/schedules/preview": {
+      "get": {
+        "summary": "Next 5 runs of a cron expression",
+        "tags": ["schedules"],
+        "parameters": [{ "name": "cron", "in": "query", "required": true, "schema": { "type": "string" }, "example": "0 4 * * *" }],
+        "responses": {
+          "200": { "description": "Next runs", "content": { "application/json": { "schema": { "type": "object", "properties": {
+            "cron": { "type": "string" },
+            "nextRuns": { "type": "array", "items": { "type": "string", "format": "date-time" } }
+          } } } } },
+          "400": { "$ref": "#/components/responses/BadRequest" }
+        }
+      }
+    }
+  },
+  "components": {
+    "securitySchemes": {
+      "bearerToken": { "type": "http", "scheme": "bearer", "description": "API token created on the API Tokens page" },
+      "sessionCookie": { "type": "apiKey", "in": "cookie", "name": "ssui_session" }
+    },
+    "responses": {
+      "BadRequest": { "description": "Invalid request", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Error" } } } },
+      "Forbidden": { "description": "Missing permission or rejected command", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Error" } } } },
+      "NotFound": { "description": "Not found", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Error" } } } },
+      "Conflict": { "description": "The server is in a state that does not allow this", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Error" } } } },
+      "Error": { "description": "Unexpected error", "content": { "application/json": { "schema": { "$ref": "#/components/schemas/Error" } } } }
+    },
+    "schemas": {
+      "Error": { "type": "object", "properties": { "error": { "type": "string" } } },
+      "Message": { "type": "object", "properties": { "message": { "type": "string" }, "steps": { "type": "array", "items": { "type": "string" } } } },
+      "Status": {
+        "type": "object",
+        "properties": {
+          "state": { "type": "string", "enum": ["Stopped", "Starting", "Ready", "Stopping", "Crashed", "Updating", "Restoring"] },
+          "stateSince": { "type": "string", "format": "date-time" },
+          "pid": { "type": "integer" },
+          "reattached": { "type": "boolean", "description": "The server was adopted after the controller restarted" },
+          "startedAt": { "type": "string", "format": "date-time" },
+          "uptimeSeconds": { "type": "integer" },
+          "world": { "type": "string" },
+          "playerCount": { "type": "integer" },
+          "restartPending": { "type": "boolean" },
+          "idle": { "type": "object", "description": "Idle timer, only present if idleStopMinutes is set", "properties": {
+            "stopAfterMinutes": { "type": "integer" },
+            "idleSince": { "type": "string", "format": "date-time" },
+            "stopAt": { "type": "string", "format": "date-time" },
+            "sleeping": { "type": "boolean", "description": "The server was stopped for being idle and can be woken" }
+          } },
+          "countdown": { "type": "object", "description": "Running restart countdown", "properties": {
+            "restartAt": { "type": "string", "format": "date-time" },
+            "reason": { "type": "string" },
+            "requestedBy": { "type": "string" },
+            "final": { "type": "boolean", "description": "The final warning was sent, the restart can no longer be cancelled" }
+          } },
+          "lastExit": { "type": "object", "properties": { "code": { "type": "integer" }, "time": { "type": "string", "format": "date-time" }, "reason": { "type": "string" }, "crashed": { "type": "boolean" } } },
+          "output": { "type": "object", "properties": { "subscribers": { "type": "integer" }, "droppedLines": { "type": "integer" }, "disconnected": { "type": "integer" } } }
+        }
+      },
+      "AuditEntry": { "type": "object", "properties": { "time": { "type": "string", "format": "date-time" }, "source": { "type": "string" }, "user": { "type": "string" }, "command": { "type": "string" }, "result": { "type": "string" } } },
+      "Backup": { "type": "object", "properties": { "index": { "type": "integer" }, "created": { "type": "string", "format": "date-time" } } },
+      "Config": {
+        "type": "object",
+        "properties": {
+          "game": { "type": "object", "properties": { "saveFileName": { "type": "string" }, "exePath": { "type": "string" }, "settings": { "type": "object", "additionalProperties": { "type": "string" } } } },
+          "controller": { "type": "object", "additionalProperties": true }
+        }
+      },
+      "Event": {
+        "type": "object",
+        "properties": {
+          "type": { "type": "string", "enum": ["ServerLoading", "ServerReady", "PlayerConnecting", "PlayerReady", "PlayerDisconnected", "WorldSaved", "Exception", "ProcessExited"] },
+          "time": { "type": "string", "format": "date-time" },
+          "player": { "type": "string" },
+          "steamId": { "type": "string" },
+          "backupIndex": { "type": "integer" },
+          "message": { "type": "string" },
+          "exitCode": { "type": "integer" },
+          "crashed": { "type": "boolean" },
+          "signature": { "type": "string" },
+          "count": { "type": "integer" }
+        }
+      },
+      "ScheduleInput": {
+        "type": "object",
+        "required": ["name", "cron", "action"],
+        "properties": {
+          "name": { "type": "string" },
+          "cron": { "type": "string", "description": "minute hour day-of-month month day-of-week in local time, or @hourly, @daily, @weekly, @monthly" },
+          "action": { "type": "string", "enum": ["restart", "stop", "start", "backup", "update", "console", "announce"] },
+          "argument": { "type": "string", "description": "Console command, announcement text, validate for update or countdown for restart" },
+          "enabled": { "type": "boolean" }
+        }
+      },
+      "Schedule": {
+        "allOf": [
+          { "$ref": "#/components/schemas/ScheduleInput" },
+          { "type": "object", "properties": {
+            "id": { "type": "string" },
+            "skipNext": { "type": "boolean" },
+            "lastRun": { "type": "string", "format": "date-time" },
+            "lastResult": { "type": "string" },
+            "nextRuns": { "type": "array", "items": { "type": "string", "format": "date-time" } }
+          } }
+        ]
+      },
+      "ScheduleRun": {
+        "type": "object",
+        "properties": {
+          "jobId": { "type": "string" },
+          "name": { "type": "string" },
+          "action": { "type": "string" },
+          "time": { "type": "string", "format": "date-time" },
+          "skipped": { "type": "boolean" },
+          "success": { "type": "boolean" },
+          "result": { "type": "string" }
+        }
+      },
+      "ExceptionGroup": {
+        "type": "object",
+        "properties": {
+          "signature": { "type": "string" },
+          "type": { "type": "string" },
+          "message": { "type": "string" },
+          "sample": { "type": "string", "description": "Latest full stack trace" },
+          "count": { "type": "integer", "description": "Occurrences since the controller started" },
+          "windowCount": { "type": "integer", "description": "Occurrences since the group was last quiet for an hour" },
+          "firstSeen": { "type": "string", "format": "date-time" },
+          "lastSeen": { "type": "string", "format": "date-time" },
+          "windowStart": { "type": "string", "format": "date-time" }
+        }
+      },
+      "RuleAction": {
+        "type": "object",
+        "properties": {
+          "type": { "type": "string", "enum": ["event", "notify", "restart"] },
+          "event": { "type": "string" },
+          "channel": { "type": "string" },
+          "message": { "type": "string" }
+        }
+      },
+      "Rule": {
+        "type": "object",
+        "properties": {
+          "name": { "type": "string" },
+          "pattern": { "type": "string" },
+          "severity": { "type": "string", "enum": ["info", "warning", "error"] },
+          "actions": { "type": "array", "items": { "$ref": "#/components/schemas/RuleAction" } }
+        }
+      },
+      "RuleMatch": {
+        "type": "object",
+        "properties": {
+          "rule": { "type": "string" },
+          "severity": { "type": "string" },
+          "fields": { "type": "object", "additionalProperties": { "type": "string" } },
+          "actions": { "type": "array", "items": { "$ref": "#/components/schemas/RuleAction" } },
+          "events": { "type": "array", "items": { "$ref": "#/components/schemas/Event" } }
+        }
+      },
+      "ResourceSample": {
+        "type": "object",
+        "properties": {
+          "time": { "type": "string", "format": "date-time" },
+          "pid": { "type": "integer" },
+          "cpuPercent": { "type": "number", "description": "CPU time used during the interval in percent of one core" },
+          "rssBytes": { "type": "integer", "description": "Resident memory" },
+          "threads": { "type": "integer" },
+          "openFiles": { "type": "integer", "description": "Open file descriptors, including sockets" },
+          "readBytesPerSec": { "type": "number", "description": "Storage reads" },
+          "writeBytesPerSec": { "type": "number", "description": "Storage writes" },
+          "players": { "type": "integer" }
+        }
+      },
+      "Player": { "type": "object", "properties": { "steamId": { "type": "string" }, "name": { "type": "string" } } },
+      "LogFile": { "type": "object", "properties": { "name": { "type": "string" }, "size": { "type": "integer" }, "modified": { "type": "string", "format": "date-time" }, "compressed": { "type": "boolean" }, "current": { "type": "boolean" } } },
+      "LogSearch": {
+        "type": "object",
+        "properties": {
+          "total": { "type": "integer" },
+          "offset": { "type": "integer" },
+          "limit": { "type": "integer" },
+          "results": { "type": "array", "items": { "type": "object", "properties": { "time": { "type": "string", "format": "date-time" }, "file": { "type": "string" }, "line": { "type": "string" }, "severity": { "type": "string" } } } }
+        }
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/events"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	policyActionsFilePath = "./UIMod/policyactions.json"
+	maxPolicyActions      = 100 // automatic actions kept in the history
+	defaultPolicyCooldown = 15 * time.Minute
+)
+
+// exceptionPolicy is a validated config.ExceptionPolicy with its sliding window of occurrences
+type exceptionPolicy struct {
+	config.ExceptionPolicy
+	signatures  map[string]bool
+	pattern     *regexp.Regexp
+	window      time.Duration
+	cooldown    time.Duration
+	occurrences []time.Time
+	lastAction  time.Time
+}
+
+// policyAction records an automatic action together with the exceptions that triggered it
+type policyAction struct {
+	Time        time.Time   `json:"time"`
+	Policy      string      `json:"policy"`
+	Action      string      `json:"action"`
+	Result      string      `json:"result"`
+	Signature   string      `json:"signature"`   // signature of the exception that crossed the threshold
+	Threshold   int         `json:"threshold"`   // as configured
+	Window      string      `json:"window"`      // as configured
+	Occurrences []time.Time `json:"occurrences"` // matching exceptions within the window
+	Trace       string      `json:"trace"`       // stack trace of the triggering exception
+}
+
+var (
+	policiesMu    sync.Mutex
+	policies      []*exceptionPolicy
+	policyActions []policyAction
+)
+
+// newExceptionPolicy checks a policy from config.json and fills in the defaults
+func newExceptionPolicy(p config.ExceptionPolicy) (*exceptionPolicy, error) {
+	policy := &exceptionPolicy{ExceptionPolicy: p, signatures: make(map[string]bool)}
+	if p.Name == "" {
+		return nil, errors.New("policy has no name")
+	}
+	if p.Threshold < 1 {
+		return nil, fmt.Errorf("policy %s: threshold must be at least 1", p.Name)
+	}
+	if p.WindowSeconds < 1 {
+		return nil, fmt.Errorf("policy %s: windowSeconds must be at least 1", p.Name)
+	}
+	if p.Action != "restart" && p.Action != "stop" {
+		return nil, fmt.Errorf("policy %s: action must be restart or stop", p.Name)
+	}
+	if p.Pattern != "" {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("policy %s: invalid pattern: %v", p.Name, err)
+		}
+		policy.pattern = re
+	}
+	for _, signature := range p.Signatures {
+		policy.signatures[signature] = true
+	}
+	policy.window = time.Duration(p.WindowSeconds) * time.Second
+	policy.cooldown = defaultPolicyCooldown
+	if p.CooldownSeconds > 0 {
+		policy.cooldown = time.Duration(p.CooldownSeconds) * time.Second
+	}
+	return policy, nil
+}
+
+// matches reports whether an exception counts towards the policy, a policy without signatures and pattern counts every exception
+func (p *exceptionPolicy) matches(e events.Event) bool {
+	if len(p.signatures) > 0 && !p.signatures[e.Signature] {
+		return false
+	}
+	if p.pattern != nil {
+		firstLine, _, _ := strings.Cut(e.Message, "\n")
+		return p.pattern.MatchString(firstLine)
+	}
+	return true
+}
+
+// StartExceptionPolicies watches the exceptions of the server and applies the policies from config.json
+func StartExceptionPolicies() {
+	loadPolicyActions()
+
+	policiesMu.Lock()
+	for _, p := range config.ExceptionPolicies {
+		policy, err := newExceptionPolicy(p)
+		if err != nil {
+			fmt.Println("⚠️Ignoring exception policy:", err)
+			continue
+		}
+		policies = append(policies, policy)
+	}
+	count := len(policies)
+	policiesMu.Unlock()
+
+	if count == 0 {
+		return
+	}
+	fmt.Printf("Watching exceptions with %d exception policies\n", count)
+
+	sub := events.Subscribe("exception policies", 256)
+	go func() {
+		for e := range sub.Events() {
+			if e.Type == events.Exception {
+				applyExceptionPolicies(e)
+			}
+		}
+	}()
+}
+
+// applyExceptionPolicies counts an exception against every matching policy and acts on the first one over its threshold
+func applyExceptionPolicies(e events.Event) {
+	policiesMu.Lock()
+	defer policiesMu.Unlock()
+
+	for _, policy := range policies {
+		if !policy.matches(e) {
+			continue
+		}
+
+		// Slide the window
+		cutoff := e.Time.Add(-policy.window)
+		recent := policy.occurrences[:0]
+		for _, t := range policy.occurrences {
+			if t.After(cutoff) {
+				recent = append(recent, t)
+			}
+		}
+		policy.occurrences = append(recent, e.Time)
+
+		if len(policy.occurrences) < policy.Threshold {
+			continue
+		}
+		if time.Since(policy.lastAction) < policy.cooldown {
+			continue
+		}
+
+		action := policyAction{
+			Time:        time.Now(),
+			Policy:      policy.Name,
+			Action:      policy.Action,
+			Signature:   e.Signature,
+			Threshold:   policy.Threshold,
+			Window:      policy.window.String(),
+			Occurrences: append([]time.Time(nil), policy.occurrences...),
+			Trace:       e.Message,
+		}
+		policy.lastAction = action.Time
+		policy.occurrences = nil
+
+		// Stopping waits for the server to exit, which must not hold up the event subscription
+		go runPolicyAction(action)
+		return
+	}
+}
+
+// runPolicyAction restarts or stops the server for a policy, alerts about it and records the outcome
+func runPolicyAction(action policyAction) {
+	notifySupervisor(fmt.Sprintf("🚨Exception policy %s: %d exceptions (signature %s) within %s, automatic %s.",
+		action.Policy, len(action.Occurrences), action.Signature, action.Window, action.Action))
+
+	var err error
+	if action.Action == "stop" {
+		_, err = localServer{}.Stop()
+	} else {
+		_, err = localServer{}.Restart()
+	}
+
+	action.Result = "done"
+	if err != nil {
+		action.Result = err.Error()
+		notifySupervisor(fmt.Sprintf("❌Automatic %s by exception policy %s failed: %v", action.Action, action.Policy, err))
+	} else if action.Action == "stop" {
+		notifySupervisor(fmt.Sprintf("🛑Server stopped by exception policy %s, start it manually once the cause is fixed.", action.Policy))
+	}
+	recordPolicyAction(action)
+}
+
+func loadPolicyActions() {
+	data, err := os.ReadFile(policyActionsFilePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Println("Error reading policy action history:", err)
+		}
+		return
+	}
+
+	policiesMu.Lock()
+	defer policiesMu.Unlock()
+	if err := json.Unmarshal(data, &policyActions); err != nil {
+		fmt.Println("Error parsing policy action history:", err)
+	}
+}
+
+// recordPolicyAction adds an action to the history on disk
+func recordPolicyAction(action policyAction) {
+	policiesMu.Lock()
+	defer policiesMu.Unlock()
+
+	policyActions = append(policyActions, action)
+	if len(policyActions) > maxPolicyActions {
+		policyActions = policyActions[len(policyActions)-maxPolicyActions:]
+	}
+
+	data, err := json.MarshalIndent(policyActions, "", "  ")
+	if err == nil {
+		err = os.WriteFile(policyActionsFilePath, data, 0644)
+	}
+	if err != nil {
+		fmt.Println("Error saving policy action history:", err)
+	}
+}
+
+type policyStatus struct {
+	config.ExceptionPolicy
+	Occurrences int        `json:"occurrences"` // within the current window
+	LastAction  *time.Time `json:"lastAction,omitempty"`
+}
+
+// GetExceptionPolicies serves the active exception policies and the automatic actions they took, newest first
+func GetExceptionPolicies(w http.ResponseWriter, r *http.Request) {
+	policiesMu.Lock()
+	response := struct {
+		Policies []policyStatus `json:"policies"`
+		Actions  []policyAction `json:"actions"`
+	}{Policies: []policyStatus{}, Actions: []policyAction{}}
+
+	for _, policy := range policies {
+		status := policyStatus{ExceptionPolicy: policy.ExceptionPolicy}
+		cutoff := time.Now().Add(-policy.window)
+		for _, t := range policy.occurrences {
+			if t.After(cutoff) {
+				status.Occurrences++
+			}
+		}
+		if !policy.lastAction.IsZero() {
+			lastAction := policy.lastAction
+			status.LastAction = &lastAction
+		}
+		response.Policies = append(response.Policies, status)
+	}
+	for i := len(policyActions) - 1; i >= 0; i-- {
+		response.Actions = append(response.Actions, policyActions[i])
+	}
+	policiesMu.Unlock()
+
+	writeJSON(w, http.StatusOK, response)
+}
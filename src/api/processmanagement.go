@@ -2,102 +2,201 @@
 package api
 
 import (
+	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/lifecycle"
+	"StationeersServerUI/src/service"
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
+)
+
+var (
+	errServerRunning    = service.ErrServerRunning
+	errServerNotRunning = service.ErrServerNotRunning
+)
+
+var (
+	stopMu            sync.Mutex // serialises stop requests, held for the whole shutdown sequence
+	worldSavedMu      sync.Mutex
+	worldSavedWaiters []chan struct{}
 )
 
 func StartServer(w http.ResponseWriter, r *http.Request) {
+	err := startServer()
+	if errors.Is(err, errServerRunning) {
+		fmt.Fprint(w, err.Error())
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Error starting server: %v", err)
+		return
+	}
+
+	fmt.Fprintf(w, "Server started.")
+}
+
+// startServer launches the dedicated server and hands the process over to the supervisor
+func startServer() error {
 	mu.Lock()
 	defer mu.Unlock()
 
 	if cmd != nil && cmd.Process != nil {
-		fmt.Fprintf(w, "Server is already running.")
-		return
+		return errServerRunning
 	}
 
-	config, err := loadConfig()
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Error loading config: %v", err), http.StatusInternalServerError)
-		return
+	previousState, _ := lifecycle.Current()
+	if err := lifecycle.Transition(lifecycle.Starting); err != nil {
+		return err
 	}
 
-	cmd = exec.Command(config.Server.ExePath, "-LOAD", config.SaveFileName, "-settings", config.Server.Settings)
-	fmt.Printf(`Load command: %s -LOAD %s -settings %s\n`, config.Server.ExePath, config.SaveFileName, config.Server.Settings)
-
-	// Capture stdout and stderr
-	stdout, err := cmd.StdoutPipe()
+	err := launchServer()
 	if err != nil {
-		fmt.Fprintf(w, "Error creating StdoutPipe: %v", err)
-		return
+		// Fall back to the state we came from, a failed launch after a crash is still a crash
+		if previousState == lifecycle.Crashed {
+			lifecycle.Transition(lifecycle.Crashed)
+		} else {
+			lifecycle.Transition(lifecycle.Stopped)
+		}
+		return err
 	}
+	return nil
+}
 
-	stderr, err := cmd.StderrPipe()
+// launchServer starts the server executable, the caller must hold mu
+func launchServer() error {
+	detached := detachEnabled()
+	config, err := loadConfig()
 	if err != nil {
-		fmt.Fprintf(w, "Error creating StderrPipe: %v", err)
-		return
+		return fmt.Errorf("error loading config: %v", err)
+	}
+
+	newCmd := exec.Command(config.Server.ExePath, "-LOAD", config.SaveFileName, "-settings", config.Server.Settings)
+	fmt.Printf("Load command: %s -LOAD %s -settings %s\n", config.Server.ExePath, config.SaveFileName, config.Server.Settings)
+
+	var stdin io.WriteCloser
+	var stdout, stderr io.ReadCloser
+	var detachedOutput *os.File
+	if detached {
+		// Console and output go through files, so a restarted controller can pick them up again
+		stdin, detachedOutput, err = prepareDetached(newCmd)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Keep stdin open to send console commands
+		stdin, err = newCmd.StdinPipe()
+		if err != nil {
+			return fmt.Errorf("error creating StdinPipe: %v", err)
+		}
+
+		// Capture stdout and stderr
+		stdout, err = newCmd.StdoutPipe()
+		if err != nil {
+			return fmt.Errorf("error creating StdoutPipe: %v", err)
+		}
+
+		stderr, err = newCmd.StderrPipe()
+		if err != nil {
+			return fmt.Errorf("error creating StderrPipe: %v", err)
+		}
 	}
 
 	// Start the command
-	err = cmd.Start()
+	err = newCmd.Start()
+	if detachedOutput != nil {
+		// The server writes through its own handle
+		detachedOutput.Close()
+	}
 	if err != nil {
-		fmt.Fprintf(w, "Error starting server: %v", err)
-		return
+		if detached {
+			stdin.Close()
+		}
+		return err
 	}
 
-	// Start reading stdout and stderr
-	go readPipe(stdout)
-	go readPipe(stderr)
+	cmd = newCmd
+	cmdReattached = false
+	cmdDetached = detached
+	attachConsole(stdin)
+	openSessionLog()
+	cmdDone = make(chan struct{})
+	cmdStartedAt = time.Now()
+	currentWorld = config.SaveFileName
+	stopRequested = false
 
-	fmt.Fprintf(w, "Server started.")
+	// A manual start supersedes any restart the supervisor has scheduled
+	cancelPendingRestart()
+
+	// Remember the process, so a restarted controller can adopt it
+	startTime, _ := procStartTime(newCmd.Process.Pid)
+	saveProcessFile(serverProcess{
+		PID:       newCmd.Process.Pid,
+		StartTime: startTime,
+		ExePath:   config.Server.ExePath,
+		World:     config.SaveFileName,
+		StartedAt: cmdStartedAt,
+		Detached:  detached,
+	})
+
+	// Start reading the output, the supervisor waits for it to be drained before reaping the process
+	var pipes sync.WaitGroup
+	if detached {
+		pipes.Add(1)
+		go tailOutput(detachedOutputPath, 0, newCmd.Process.Pid, startTime, &pipes)
+	} else {
+		pipes.Add(2)
+		go readPipe(stdout, &pipes)
+		go readPipe(stderr, &pipes)
+	}
+	go superviseProcess(newCmd, &pipes, cmdDone, cmdStartedAt)
+
+	return nil
 }
 
-func readPipe(pipe io.ReadCloser) {
+func readPipe(pipe io.ReadCloser, pipes *sync.WaitGroup) {
+	defer pipes.Done()
 	scanner := bufio.NewScanner(pipe)
 	for scanner.Scan() {
 		output := scanner.Text()
-		clientsMu.Lock()
-		for _, clientChan := range clients {
-			clientChan <- output
-		}
-		clientsMu.Unlock()
+		observeOutput(output)
+		broadcastOutput(output)
 	}
 	if err := scanner.Err(); err != nil {
-		output := fmt.Sprintf("Error reading pipe: %v", err)
-		clientsMu.Lock()
-		for _, clientChan := range clients {
-			clientChan <- output
-		}
-		clientsMu.Unlock()
+		broadcastOutput(fmt.Sprintf("Error reading pipe: %v", err))
 	}
 }
 
-func GetOutput(w http.ResponseWriter, r *http.Request) {
-	// Create a new channel for this client
-	clientChan := make(chan string)
-
-	// Register the client
-	clientsMu.Lock()
-	clients = append(clients, clientChan)
-	clientsMu.Unlock()
-
-	// Ensure the channel is removed when the client disconnects
-	defer func() {
-		clientsMu.Lock()
-		for i, ch := range clients {
-			if ch == clientChan {
-				clients = append(clients[:i], clients[i+1:]...)
-				break
-			}
-		}
-		clientsMu.Unlock()
-		close(clientChan)
-	}()
+// observeOutput drives the lifecycle from markers in the server log and publishes the events they stand for
+func observeOutput(output string) {
+	// Stack frames belong to the exception before them, they are not matched against the rules
+	if !continueException(output) {
+		publishOutputEvents(output)
+	}
+	if strings.Contains(output, "World Saved") {
+		notifyWorldSaved()
+	}
+}
+
+// broadcastOutput records a line in the output history and queues it for every output client
+func broadcastOutput(output string) {
+	line := outputHub.publish(output)
+	writeSessionLog(line)
+}
 
+// GetOutput streams the server output as SSE. New clients first receive the last lines of the history
+// (?replay=N, default 200), reconnecting clients resume after the id sent in the Last-Event-ID header.
+// A client that falls behind is disconnected so it can resume from the history, ?overflow=drop
+// makes it skip the oldest queued lines instead.
+func GetOutput(w http.ResponseWriter, r *http.Request) {
 	// Set headers for SSE
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -110,41 +209,222 @@ func GetOutput(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	for msg := range clientChan {
-		fmt.Fprintf(w, "data: %s\n\n", msg)
-		flusher.Flush()
+	policy := disconnectSlow
+	if r.URL.Query().Get("overflow") == "drop" {
+		policy = dropOldest
+	}
+
+	// Register the client together with its replay, so no line is missed or sent twice
+	sub, replay := outputHub.subscribe(outputQueueSize, policy, func(history *logHistory) []logLine {
+		return replayLines(history, r)
+	})
+	defer outputHub.unsubscribe(sub)
+	defer trackSSEClient("output")()
+
+	for _, line := range replay {
+		writeOutputEvent(w, line)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case msg, ok := <-sub.lines:
+			if !ok {
+				// Disconnected by the hub for falling behind
+				return
+			}
+			writeOutputEvent(w, msg)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// replayLines picks the history lines a connecting output client has not seen yet
+func replayLines(history *logHistory, r *http.Request) []logLine {
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		if seq, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+			if lines, ok := history.since(seq); ok {
+				return lines
+			}
+		}
 	}
+
+	count := defaultReplayLines
+	if replay := r.URL.Query().Get("replay"); replay != "" {
+		if n, err := strconv.Atoi(replay); err == nil && n >= 0 {
+			count = n
+		}
+	}
+	return history.last(count)
+}
+
+func writeOutputEvent(w http.ResponseWriter, line logLine) {
+	fmt.Fprintf(w, "id: %d\ndata: %s\n\n", line.Seq, line.Text)
 }
 
 func StopServer(w http.ResponseWriter, r *http.Request) {
-	mu.Lock()
-	defer mu.Unlock()
+	flusher, _ := w.(http.Flusher)
 
-	if cmd == nil || cmd.Process == nil {
-		fmt.Fprintf(w, "Server is not running.")
+	// Report every step of the shutdown to the caller as it happens
+	report := func(step string) {
+		fmt.Println(step)
+		fmt.Fprintln(w, step)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	err := stopServer(report)
+	if errors.Is(err, errServerNotRunning) {
+		// Stopping also clears a pending automatic restart after a crash
+		if cancelPendingRestart() {
+			fmt.Fprintf(w, "Server is not running. Pending automatic restart cancelled.")
+			return
+		}
+		fmt.Fprint(w, err.Error())
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Error stopping server: %v", err)
 		return
 	}
 
-	// Attempt a graceful shutdown
-	err := cmd.Process.Signal(syscall.SIGTERM)
+	fmt.Fprintf(w, "Server stopped.")
+}
+
+// stopServer asks the server to save and quit, then escalates to SIGTERM and SIGKILL if it does not exit in time
+func stopServer(report func(string)) error {
+	stopMu.Lock()
+	defer stopMu.Unlock()
+
+	mu.Lock()
+	if cmd == nil || cmd.Process == nil {
+		mu.Unlock()
+		lifecycle.TransitionFrom(lifecycle.Crashed, lifecycle.Stopped)
+		return errServerNotRunning
+	}
+	c := cmd
+	done := cmdDone
+
+	// Tell the supervisor that the upcoming exit is intended
+	stopRequested = true
+	mu.Unlock()
+
+	cancelPendingRestart()
+	endCountdown("the server is being stopped")
+	lifecycle.Transition(lifecycle.Stopping)
+
+	exited := func(timeout time.Duration) bool {
+		select {
+		case <-done:
+			return true
+		case <-time.After(timeout):
+			return false
+		}
+	}
+
+	// Save the world first so the last minutes of play are not lost
+	if config.StopSaveCommand != "" {
+		saved := waitForWorldSaved()
+		if err := sendSystemCommand(config.StopSaveCommand); err != nil {
+			report(fmt.Sprintf("Could not send save command: %v", err))
+		} else {
+			report(fmt.Sprintf("Sent '%s', waiting up to %ds for the world to be saved...", config.StopSaveCommand, config.StopSaveTimeoutSecs))
+			select {
+			case <-saved:
+				report("World saved.")
+			case <-done:
+				report("Server exited while saving.")
+				return finishStop(c)
+			case <-time.After(time.Duration(config.StopSaveTimeoutSecs) * time.Second):
+				report("Timed out waiting for the world to be saved.")
+			}
+		}
+	}
+
+	if config.StopQuitCommand != "" {
+		if err := sendSystemCommand(config.StopQuitCommand); err != nil {
+			report(fmt.Sprintf("Could not send quit command: %v", err))
+		} else {
+			report(fmt.Sprintf("Sent '%s', waiting up to %ds for the server to exit...", config.StopQuitCommand, config.StopQuitTimeoutSecs))
+			if exited(time.Duration(config.StopQuitTimeoutSecs) * time.Second) {
+				return finishStop(c)
+			}
+			report("Server did not quit in time.")
+		}
+	}
+
+	// Escalate to signals
+	err := c.Process.Signal(syscall.SIGTERM)
 	if err != nil {
 		// If sending SIGTERM fails, attempt to kill the process
-		fmt.Fprintf(w, "(Known issue) Error sending SIGTERM to server: %v. Killing the process.\n", err)
-
-		err = cmd.Process.Kill()
-		if err != nil {
-			fmt.Fprintf(w, "Error killing server process: %v", err)
-			return
+		report(fmt.Sprintf("(Known issue) Error sending SIGTERM to server: %v. Killing the process.", err))
+	} else {
+		report(fmt.Sprintf("Sent SIGTERM, waiting up to %ds for the server to exit...", config.StopTermTimeoutSecs))
+		if exited(time.Duration(config.StopTermTimeoutSecs) * time.Second) {
+			return finishStop(c)
 		}
+		report("Server ignored SIGTERM, killing the process.")
 	}
 
-	// Wait for the process to exit
-	err = cmd.Wait()
-	if err != nil && !strings.Contains(err.Error(), "exit status") {
-		fmt.Fprintf(w, "Error waiting for process: %v", err)
-		return
+	err = c.Process.Kill()
+	if err != nil {
+		return fmt.Errorf("error killing server process: %v", err)
 	}
 
-	cmd = nil
-	fmt.Fprintf(w, "Server stopped.")
+	// Wait for the supervisor to reap the process
+	<-done
+	return finishStop(c)
+}
+
+// finishStop clears the stopped process once the supervisor has reaped it
+func finishStop(c *exec.Cmd) error {
+	mu.Lock()
+	clearProcess(c)
+	mu.Unlock()
+
+	lifecycle.Transition(lifecycle.Stopped)
+	closeSessionLog()
+	return nil
+}
+
+// saveWorld makes the running server save, the game writes a backup that the backup watcher keeps in Safebackups
+func saveWorld() error {
+	command := config.StopSaveCommand
+	if command == "" {
+		command = "save"
+	}
+
+	saved := waitForWorldSaved()
+	if err := sendSystemCommand(command); err != nil {
+		return err
+	}
+	select {
+	case <-saved:
+		return nil
+	case <-time.After(time.Duration(config.StopSaveTimeoutSecs) * time.Second):
+		return fmt.Errorf("timed out after %ds waiting for the world to be saved", config.StopSaveTimeoutSecs)
+	}
+}
+
+// waitForWorldSaved returns a channel that is closed the next time the server reports a saved world
+func waitForWorldSaved() <-chan struct{} {
+	worldSavedMu.Lock()
+	defer worldSavedMu.Unlock()
+
+	ch := make(chan struct{})
+	worldSavedWaiters = append(worldSavedWaiters, ch)
+	return ch
+}
+
+func notifyWorldSaved() {
+	worldSavedMu.Lock()
+	defer worldSavedMu.Unlock()
+
+	for _, ch := range worldSavedWaiters {
+		close(ch)
+	}
+	worldSavedWaiters = nil
 }
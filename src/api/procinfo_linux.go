@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// procAvailable reports whether process details can be read from /proc, which re-attaching and resource monitoring need
+const procAvailable = true
+
+// clockTick is the unit of the CPU times in /proc, USER_HZ is 100 on every common Linux build
+const clockTick = time.Second / 100
+
+// readProcStat returns the fields of /proc/<pid>/stat after the command name, so fields[0] is the state
+func readProcStat(pid int) ([]string, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return nil, err
+	}
+	// The command name is in parentheses and may contain spaces, the fields follow the last ")"
+	stat := string(data)
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return nil, fmt.Errorf("unexpected format of /proc/%d/stat", pid)
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 22 {
+		return nil, fmt.Errorf("unexpected format of /proc/%d/stat", pid)
+	}
+	return fields, nil
+}
+
+// procStartTime returns the start time of a running process. It is counted in clock ticks
+// since boot and tells a reused PID apart.
+func procStartTime(pid int) (uint64, error) {
+	fields, err := readProcStat(pid)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(fields[19], 10, 64)
+}
+
+// processRunning reports whether the process with the pid is still the one that started at startTime.
+// Zombies count as gone, they only wait to be reaped.
+func processRunning(pid int, startTime uint64) bool {
+	fields, err := readProcStat(pid)
+	if err != nil {
+		return false
+	}
+	started, err := strconv.ParseUint(fields[19], 10, 64)
+	return err == nil && started == startTime && fields[0] != "Z" && fields[0] != "X"
+}
+
+// readProcUsage reads the resource usage of a process so far
+func readProcUsage(pid int) (procUsage, error) {
+	fields, err := readProcStat(pid)
+	if err != nil {
+		return procUsage{}, err
+	}
+	number := func(i int) uint64 {
+		n, _ := strconv.ParseUint(fields[i], 10, 64)
+		return n
+	}
+
+	usage := procUsage{
+		CPUTime:  time.Duration(number(11)+number(12)) * clockTick, // utime + stime
+		Threads:  int(number(17)),
+		RSSBytes: number(21) * uint64(os.Getpagesize()),
+	}
+	if fds, err := os.ReadDir(fmt.Sprintf("/proc/%d/fd", pid)); err == nil {
+		usage.OpenFiles = len(fds)
+	}
+	usage.ReadBytes, usage.WriteBytes = readProcIO(pid)
+	return usage, nil
+}
+
+// readProcIO returns the bytes a process read from and wrote to storage, zero if /proc/<pid>/io cannot be read
+func readProcIO(pid int) (read, written uint64) {
+	file, err := os.Open(fmt.Sprintf("/proc/%d/io", pid))
+	if err != nil {
+		return 0, 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), ": ")
+		if !ok {
+			continue
+		}
+		n, _ := strconv.ParseUint(value, 10, 64)
+		switch name {
+		case "read_bytes":
+			read = n
+		case "write_bytes":
+			written = n
+		}
+	}
+	return read, written
+}
+
+// makeFifo creates the named pipe a detached server reads its console from
+func makeFifo(path string) error {
+	return syscall.Mkfifo(path, 0600)
+}
+
+// detachedProcAttr starts a detached server in its own session, so signals to the controller's
+// terminal or process group do not reach it
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,33 @@
+//go:build !linux
+
+package api
+
+import (
+	"errors"
+	"syscall"
+)
+
+// procAvailable reports whether process details can be read from /proc, which re-attaching and resource monitoring need
+const procAvailable = false
+
+var errNoProc = errors.New("process details from /proc are only available on Linux")
+
+func procStartTime(pid int) (uint64, error) {
+	return 0, errNoProc
+}
+
+func processRunning(pid int, startTime uint64) bool {
+	return false
+}
+
+func readProcUsage(pid int) (procUsage, error) {
+	return procUsage{}, errNoProc
+}
+
+func makeFifo(path string) error {
+	return errNoProc
+}
+
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/events"
+	"StationeersServerUI/src/lifecycle"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	processFilePath     = "./UIMod/process.json"
+	detachedOutputPath  = "./logs/detached-output.txt"   // output of a detached server, rewritten on every start
+	detachedConsolePath = "./logs/detached-console.fifo" // named pipe a detached server reads its console from
+	outputPollInterval  = 250 * time.Millisecond
+	processPollInterval = time.Second
+)
+
+// serverProcess is what the controller remembers about the running server, so it can adopt the
+// server again after the controller itself restarts
+type serverProcess struct {
+	PID       int       `json:"pid"`
+	StartTime uint64    `json:"startTime"` // from /proc, tells a reused PID apart
+	ExePath   string    `json:"exePath"`
+	World     string    `json:"world"`
+	StartedAt time.Time `json:"startedAt"`
+	Detached  bool      `json:"detached"`
+}
+
+var (
+	cmdReattached bool // the current server was adopted from a previous run of the controller, guarded by mu
+	cmdDetached   bool // the current server survives the controller, guarded by mu
+)
+
+// detachEnabled reports whether new servers are started detached from the controller
+func detachEnabled() bool {
+	if config.DetachServer && !procAvailable {
+		fmt.Println("⚠️detachServer is only supported on Linux, starting the server attached")
+		return false
+	}
+	return config.DetachServer
+}
+
+// prepareDetached points the console and output of the server at files that outlive the controller.
+// The returned console stays open in the controller, the output file must be closed once the server started.
+func prepareDetached(c *exec.Cmd) (console *os.File, output *os.File, err error) {
+	if err := os.MkdirAll(logsDir, os.ModePerm); err != nil {
+		return nil, nil, err
+	}
+
+	os.Remove(detachedConsolePath)
+	if err := makeFifo(detachedConsolePath); err != nil {
+		return nil, nil, fmt.Errorf("error creating console pipe: %v", err)
+	}
+	// Opened for reading and writing, so opening does not block and the server never reads EOF
+	console, err = os.OpenFile(detachedConsolePath, os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening console pipe: %v", err)
+	}
+
+	output, err = os.OpenFile(detachedOutputPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		console.Close()
+		return nil, nil, fmt.Errorf("error creating output file: %v", err)
+	}
+
+	c.Stdin = console
+	c.Stdout = output
+	c.Stderr = output
+	c.SysProcAttr = detachedProcAttr()
+	return console, output, nil
+}
+
+// tailOutput follows the output file of a detached server from offset until the process is gone
+func tailOutput(path string, offset int64, pid int, startTime uint64, pipes *sync.WaitGroup) {
+	defer pipes.Done()
+
+	file, err := os.Open(path)
+	if err != nil {
+		broadcastOutput(fmt.Sprintf("Error reading server output: %v", err))
+		return
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		broadcastOutput(fmt.Sprintf("Error reading server output: %v", err))
+		return
+	}
+
+	reader := bufio.NewReader(file)
+	partial := ""
+	exited := false
+	for {
+		line, err := reader.ReadString('\n')
+		partial += line
+		if err == nil {
+			output := strings.TrimRight(partial, "\r\n")
+			partial = ""
+			observeOutput(output)
+			broadcastOutput(output)
+			continue
+		}
+		if err != io.EOF {
+			broadcastOutput(fmt.Sprintf("Error reading server output: %v", err))
+			return
+		}
+		if exited {
+			if partial != "" {
+				observeOutput(partial)
+				broadcastOutput(partial)
+			}
+			return
+		}
+		if !processRunning(pid, startTime) {
+			// Read once more, the server may have written its last lines right before exiting
+			exited = true
+			continue
+		}
+		time.Sleep(outputPollInterval)
+	}
+}
+
+// saveProcessFile remembers the started server for the next run of the controller
+func saveProcessFile(p serverProcess) {
+	if !procAvailable {
+		return
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err == nil {
+		err = os.WriteFile(processFilePath, data, 0600)
+	}
+	if err != nil {
+		fmt.Println("⚠️Error saving", processFilePath, err)
+	}
+}
+
+func removeProcessFile() {
+	if err := os.Remove(processFilePath); err != nil && !os.IsNotExist(err) {
+		fmt.Println("⚠️Error removing", processFilePath, err)
+	}
+}
+
+// ReattachServer adopts a server that a previous run of the controller left running.
+// Status and stop work for every adopted server, output and console only if it was started detached.
+func ReattachServer() {
+	data, err := os.ReadFile(processFilePath)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		fmt.Println("⚠️Error reading", processFilePath, err)
+		return
+	}
+
+	var p serverProcess
+	if err := json.Unmarshal(data, &p); err != nil {
+		fmt.Printf("⚠️Error parsing %s: %v\n", processFilePath, err)
+		removeProcessFile()
+		return
+	}
+	if !processRunning(p.PID, p.StartTime) {
+		fmt.Printf("Server process %d of the previous run is no longer running\n", p.PID)
+		removeProcessFile()
+		return
+	}
+	proc, err := os.FindProcess(p.PID)
+	if err != nil {
+		fmt.Printf("⚠️Error finding server process %d: %v\n", p.PID, err)
+		return
+	}
+
+	mu.Lock()
+	if cmd != nil {
+		mu.Unlock()
+		return
+	}
+	if err := lifecycle.Transition(lifecycle.Starting); err != nil {
+		mu.Unlock()
+		fmt.Printf("⚠️Cannot re-attach to server process %d: %v\n", p.PID, err)
+		return
+	}
+
+	c := &exec.Cmd{Path: p.ExePath, Args: []string{p.ExePath}, Process: proc}
+	cmd = c
+	cmdReattached = true
+	cmdDetached = p.Detached
+	cmdDone = make(chan struct{})
+	cmdStartedAt = p.StartedAt
+	currentWorld = p.World
+	stopRequested = false
+	openSessionLog()
+
+	var pipes sync.WaitGroup
+	console := "without its console and output, it was not started detached"
+	if p.Detached {
+		if stdin, err := os.OpenFile(detachedConsolePath, os.O_RDWR, 0); err != nil {
+			fmt.Println("⚠️Error opening the console pipe of the server:", err)
+			attachConsole(nil)
+		} else {
+			attachConsole(stdin)
+		}
+		// Only new output, the lines before are in the session log of the previous run
+		var offset int64
+		if info, err := os.Stat(detachedOutputPath); err == nil {
+			offset = info.Size()
+		}
+		pipes.Add(1)
+		go tailOutput(detachedOutputPath, offset, p.PID, p.StartTime, &pipes)
+		console = "with console and output"
+	} else {
+		attachConsole(nil)
+	}
+	go watchAdoptedProcess(c, p.StartTime, &pipes, cmdDone, p.StartedAt)
+	mu.Unlock()
+
+	// The ready marker was logged long ago, the server has been running all along
+	publishEvents([]events.Event{{Type: events.ServerReady, Time: time.Now(), Message: "re-attached"}})
+	notifySupervisor(fmt.Sprintf("🔗 Re-attached to server process %d, running for %s, %s.", p.PID, time.Since(p.StartedAt).Round(time.Second), console))
+}
+
+// watchAdoptedProcess waits for an adopted server to exit. It is not a child of this controller,
+// so it is polled through /proc and its exit code is unknown.
+func watchAdoptedProcess(c *exec.Cmd, startTime uint64, pipes *sync.WaitGroup, done chan struct{}, startedAt time.Time) {
+	for processRunning(c.Process.Pid, startTime) {
+		time.Sleep(processPollInterval)
+	}
+	pipes.Wait()
+	processExited(c, done, startedAt, -1, "exited, the exit code of a re-attached server is unknown")
+}
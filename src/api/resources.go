@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	resourceSampleInterval = 5 * time.Second
+	resourceHistorySize    = 720 // one hour of samples
+	resourceQueueSize      = 16  // samples queued per stream client before it misses some
+)
+
+// procUsage is the resource usage of a process since it started, as read from /proc
+type procUsage struct {
+	CPUTime    time.Duration
+	RSSBytes   uint64
+	Threads    int
+	OpenFiles  int
+	ReadBytes  uint64
+	WriteBytes uint64
+}
+
+// resourceSample is the resource usage of the server during one sample interval
+type resourceSample struct {
+	Time             time.Time `json:"time"`
+	PID              int       `json:"pid"`
+	CPUPercent       float64   `json:"cpuPercent"` // of one core, above 100 when the server keeps several cores busy
+	RSSBytes         uint64    `json:"rssBytes"`
+	Threads          int       `json:"threads"`
+	OpenFiles        int       `json:"openFiles"`
+	ReadBytesPerSec  float64   `json:"readBytesPerSec"`
+	WriteBytesPerSec float64   `json:"writeBytesPerSec"`
+	Players          int       `json:"players"`
+}
+
+var (
+	resourcesMu      sync.Mutex
+	resourceHistory  []resourceSample // oldest first
+	resourceClients  = make(map[chan resourceSample]bool)
+	resourcesStarted bool
+)
+
+// The counters of the previous sample, only used by the monitor goroutine
+var (
+	lastUsage     procUsage
+	lastUsagePID  int
+	lastUsageTime time.Time
+)
+
+// StartResourceMonitor samples the resource usage of the server from /proc every few seconds
+func StartResourceMonitor() {
+	if !procAvailable {
+		fmt.Println("Resource monitoring reads /proc and is only available on Linux")
+		return
+	}
+	resourcesStarted = true
+
+	go func() {
+		ticker := time.NewTicker(resourceSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				sampleResources(now)
+			case <-shuttingDown:
+				return
+			}
+		}
+	}()
+}
+
+// sampleResources records the usage since the previous sample. The first sample of a process only
+// primes the counters, so the history never shows the usage of the whole startup as one interval.
+func sampleResources(now time.Time) {
+	mu.Lock()
+	pid := 0
+	if cmd != nil && cmd.Process != nil {
+		pid = cmd.Process.Pid
+	}
+	mu.Unlock()
+
+	var usage procUsage
+	var err error
+	if pid != 0 {
+		usage, err = readProcUsage(pid)
+	}
+	if pid == 0 || err != nil {
+		// Not running, or it just exited
+		lastUsagePID = 0
+		return
+	}
+
+	previous, previousPID, previousTime := lastUsage, lastUsagePID, lastUsageTime
+	lastUsage, lastUsagePID, lastUsageTime = usage, pid, now
+	if previousPID != pid {
+		return
+	}
+
+	elapsed := now.Sub(previousTime).Seconds()
+	sample := resourceSample{
+		Time:             now,
+		PID:              pid,
+		CPUPercent:       (usage.CPUTime - previous.CPUTime).Seconds() / elapsed * 100,
+		RSSBytes:         usage.RSSBytes,
+		Threads:          usage.Threads,
+		OpenFiles:        usage.OpenFiles,
+		ReadBytesPerSec:  counterRate(previous.ReadBytes, usage.ReadBytes, elapsed),
+		WriteBytesPerSec: counterRate(previous.WriteBytes, usage.WriteBytes, elapsed),
+		Players:          playerCount(),
+	}
+	recordResourceSample(sample)
+	checkMemoryLimit(sample)
+}
+
+func counterRate(previous, current uint64, seconds float64) float64 {
+	if current < previous {
+		return 0
+	}
+	return float64(current-previous) / seconds
+}
+
+func recordResourceSample(sample resourceSample) {
+	resourcesMu.Lock()
+	defer resourcesMu.Unlock()
+
+	resourceHistory = append(resourceHistory, sample)
+	if len(resourceHistory) > resourceHistorySize {
+		resourceHistory = resourceHistory[len(resourceHistory)-resourceHistorySize:]
+	}
+	for client := range resourceClients {
+		select {
+		case client <- sample:
+		default:
+			// A slow client misses the sample, the next one is complete on its own
+		}
+	}
+}
+
+// resourceSamplesSince returns the samples taken after since, oldest first
+func resourceSamplesSince(since time.Time) []resourceSample {
+	resourcesMu.Lock()
+	defer resourcesMu.Unlock()
+
+	samples := []resourceSample{}
+	for _, sample := range resourceHistory {
+		if sample.Time.After(since) {
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
+
+// GetResources serves the sampled resource usage of the server, oldest first. ?minutes= limits the history,
+// the last hour is kept.
+func GetResources(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if minutes, err := strconv.Atoi(r.URL.Query().Get("minutes")); err == nil && minutes > 0 {
+		since = time.Now().Add(-time.Duration(minutes) * time.Minute)
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Available       bool             `json:"available"`
+		IntervalSeconds int              `json:"intervalSeconds"`
+		Samples         []resourceSample `json:"samples"`
+	}{resourcesStarted, int(resourceSampleInterval / time.Second), resourceSamplesSince(since)})
+}
+
+// StreamResources sends every new resource sample as SSE
+func StreamResources(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	client := make(chan resourceSample, resourceQueueSize)
+	resourcesMu.Lock()
+	resourceClients[client] = true
+	resourcesMu.Unlock()
+	defer func() {
+		resourcesMu.Lock()
+		delete(resourceClients, client)
+		resourcesMu.Unlock()
+	}()
+	defer trackSSEClient("resources")()
+	flusher.Flush()
+
+	for {
+		select {
+		case sample := <-client:
+			data, _ := json.Marshal(sample)
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
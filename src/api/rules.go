@@ -0,0 +1,50 @@
+package api
+
+import (
+	"StationeersServerUI/src/events"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+type rulesResponse struct {
+	File     string        `json:"file"`
+	LoadedAt time.Time     `json:"loadedAt"`
+	Error    string        `json:"error,omitempty"` // why the file on disk is not active
+	Rules    []events.Rule `json:"rules"`
+}
+
+// GetRules serves the active log rules and the error of the last reload, if any
+func GetRules(w http.ResponseWriter, r *http.Request) {
+	rules, loadedAt, err := events.Rules()
+	response := rulesResponse{File: events.RulesFilePath, LoadedAt: loadedAt, Rules: rules}
+	if err != nil {
+		response.Error = err.Error()
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// TestRules runs {"line": "..."} against every active rule and reports the matches, no action is carried out
+func TestRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Invalid request method")
+		return
+	}
+
+	var request struct {
+		Line string `json:"line"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&request); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid JSON body, expected {\"line\": \"...\"}")
+		return
+	}
+
+	matches := events.MatchLine(request.Line)
+	if matches == nil {
+		matches = []events.Match{}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Line    string         `json:"line"`
+		Matches []events.Match `json:"matches"`
+	}{request.Line, matches})
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"StationeersServerUI/src/auth"
+	"StationeersServerUI/src/scheduler"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ServeSchedulesPage serves the scheduled tasks page
+func ServeSchedulesPage(w http.ResponseWriter, r *http.Request) {
+	http.ServeFile(w, r, "./UIMod/schedules.html")
+}
+
+// GetSchedules serves the scheduled tasks with their next runs
+func GetSchedules(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, scheduler.List())
+}
+
+// CreateSchedule adds a scheduled task from a JSON body
+func CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	job, ok := decodeSchedule(w, r)
+	if !ok {
+		return
+	}
+	created, err := scheduler.Create(job)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	fmt.Printf("User %s scheduled %s (%s %s) at %s\n", auth.Username(r), created.Name, created.Action, created.Argument, created.Cron)
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// UpdateSchedule replaces the settings of the scheduled task {id}
+func UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	job, ok := decodeSchedule(w, r)
+	if !ok {
+		return
+	}
+	updated, err := scheduler.Update(r.PathValue("id"), job)
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+	fmt.Printf("User %s changed schedule %s\n", auth.Username(r), updated.Name)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteSchedule removes the scheduled task {id}
+func DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := scheduler.Delete(id); err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+	fmt.Printf("User %s deleted schedule %s\n", auth.Username(r), id)
+	writeJSON(w, http.StatusOK, messageResponse{Message: "Schedule deleted."})
+}
+
+// SkipSchedule makes the scheduled task {id} skip its next occurrence, ?skip=false undoes it
+func SkipSchedule(w http.ResponseWriter, r *http.Request) {
+	skip := r.URL.Query().Get("skip") != "false"
+	job, err := scheduler.SetSkipNext(r.PathValue("id"), skip)
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+	fmt.Printf("User %s set skip next of schedule %s to %v\n", auth.Username(r), job.Name, skip)
+	writeJSON(w, http.StatusOK, job)
+}
+
+// GetScheduleHistory serves the runs of the scheduled tasks, newest first
+func GetScheduleHistory(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, scheduler.History())
+}
+
+// PreviewSchedule lists the next times ?cron= fires, so expressions can be checked before saving
+func PreviewSchedule(w http.ResponseWriter, r *http.Request) {
+	expr := r.URL.Query().Get("cron")
+	schedule, err := scheduler.ParseCron(expr)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Cron     string      `json:"cron"`
+		NextRuns []time.Time `json:"nextRuns"`
+	}{expr, schedule.NextRuns(time.Now(), 5)})
+}
+
+func decodeSchedule(w http.ResponseWriter, r *http.Request) (scheduler.Job, bool) {
+	var job scheduler.Job
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&job); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return job, false
+	}
+	return job, true
+}
+
+func writeScheduleError(w http.ResponseWriter, err error) {
+	if errors.Is(err, scheduler.ErrNotFound) {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSONError(w, http.StatusBadRequest, err.Error())
+}
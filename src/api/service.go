@@ -0,0 +1,83 @@
+package api
+
+import (
+	"StationeersServerUI/src/service"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// localServer implements service.Server on the process managed by this package
+type localServer struct{}
+
+// NewService returns the in-process server control used by the Discord bot
+func NewService() service.Server {
+	return localServer{}
+}
+
+func (localServer) Start() error {
+	return startServer()
+}
+
+func (localServer) Stop() (service.StopResult, error) {
+	var result service.StopResult
+	err := stopServer(func(step string) {
+		fmt.Println(step)
+		result.Steps = append(result.Steps, step)
+	})
+	if errors.Is(err, errServerNotRunning) {
+		result.RestartCancelled = cancelPendingRestart()
+	}
+	return result, err
+}
+
+func (s localServer) Restart() (service.StopResult, error) {
+	result, err := s.Stop()
+	if err != nil && !errors.Is(err, errServerNotRunning) {
+		return result, err
+	}
+	if err := startServer(); err != nil {
+		return result, err
+	}
+	requestedRestarts.Add(1)
+	return result, nil
+}
+
+func (localServer) RestartWithCountdown(reason, requestedBy string) (time.Time, error) {
+	return startCountdownRestart(reason, requestedBy)
+}
+
+func (localServer) CancelRestart(requestedBy string) error {
+	return cancelCountdownRestart(requestedBy)
+}
+
+func (localServer) Wake(requestedBy string) error {
+	return wakeServer(requestedBy)
+}
+
+func (localServer) ListBackups() ([]service.BackupInfo, error) {
+	return listBackups()
+}
+
+func (localServer) RestoreBackup(index int) error {
+	return restoreBackup(index)
+}
+
+func (localServer) SaveWorld() error {
+	return saveWorld()
+}
+
+func (localServer) Update(validate bool) error {
+	return updateServer(validate)
+}
+
+func (localServer) SendConsoleCommand(source, user, command string) error {
+	return SendConsoleCommand(source, user, command)
+}
+
+func (localServer) SetBanned(steamID string, banned bool) (bool, error) {
+	if !validSteamID(steamID) {
+		return false, fmt.Errorf("invalid SteamID %q, expected the numeric SteamID64", steamID)
+	}
+	return setBanned(steamID, banned)
+}
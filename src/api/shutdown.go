@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	shuttingDown      = make(chan struct{}) // closed when the controller shuts down, ends the background routines
+	backupWatcherDone = make(chan struct{}) // closed when WatchBackupDir returns, no backup copies are added after that
+	backupCopies      sync.WaitGroup        // backups waiting to be copied to Safebackups
+)
+
+// Shutdown prepares the controller to exit: it stops the server with the usual save and quit sequence,
+// ends the backup routines and waits for pending backup copies and log compression.
+// A detached server is saved and left running for the next run of the controller to re-attach.
+func Shutdown() error {
+	mu.Lock()
+	running := cmd != nil && cmd.Process != nil
+	detached := cmdDetached
+	mu.Unlock()
+
+	var err error
+	switch {
+	case running && detached:
+		notifySupervisor("💾 Controller shutting down, saving the world. The detached server keeps running.")
+		if err = saveWorld(); err != nil {
+			err = fmt.Errorf("error saving the world: %v", err)
+		}
+		closeSessionLog()
+	case running:
+		notifySupervisor("🛑 Controller shutting down, stopping the server.")
+		err = stopServer(func(step string) { fmt.Println(step) })
+	default:
+		cancelPendingRestart()
+	}
+
+	close(shuttingDown)
+	<-backupWatcherDone
+	backupCopies.Wait()
+	compressingWg.Wait()
+	return err
+}
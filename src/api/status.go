@@ -0,0 +1,80 @@
+package api
+
+import (
+	"StationeersServerUI/src/lifecycle"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+type exitStatus struct {
+	Code    int       `json:"code"`
+	Time    time.Time `json:"time"`
+	Reason  string    `json:"reason"`
+	Crashed bool      `json:"crashed"`
+}
+
+type serverStatus struct {
+	State          lifecycle.State   `json:"state"`
+	StateSince     time.Time         `json:"stateSince"`
+	PID            int               `json:"pid,omitempty"`
+	Reattached     bool              `json:"reattached,omitempty"`
+	StartedAt      *time.Time        `json:"startedAt,omitempty"`
+	UptimeSeconds  int64             `json:"uptimeSeconds"`
+	World          string            `json:"world"`
+	PlayerCount    int               `json:"playerCount"`
+	RestartPending bool              `json:"restartPending"`
+	Countdown      *restartCountdown `json:"countdown,omitempty"`
+	Idle           *idleStatus       `json:"idle,omitempty"`
+	LastExit       *exitStatus       `json:"lastExit,omitempty"`
+	Output         hubStats          `json:"output"`
+}
+
+// currentStatus collects the lifecycle state and process details of the dedicated server
+func currentStatus() serverStatus {
+	var status serverStatus
+	status.State, status.StateSince = lifecycle.Current()
+
+	mu.Lock()
+	if cmd != nil && cmd.Process != nil {
+		startedAt := cmdStartedAt
+		status.PID = cmd.Process.Pid
+		status.Reattached = cmdReattached
+		status.StartedAt = &startedAt
+		status.UptimeSeconds = int64(time.Since(cmdStartedAt).Seconds())
+		status.World = currentWorld
+	}
+	mu.Unlock()
+
+	if status.World == "" {
+		if xmlConfig, err := loadConfig(); err == nil {
+			status.World = xmlConfig.SaveFileName
+		}
+	}
+
+	status.PlayerCount = playerCount()
+
+	supervisorMu.Lock()
+	status.RestartPending = restartTimer != nil
+	if !lastExit.Time.IsZero() {
+		status.LastExit = &exitStatus{
+			Code:    lastExit.Code,
+			Time:    lastExit.Time,
+			Reason:  lastExit.Reason,
+			Crashed: lastExit.Crashed,
+		}
+	}
+	supervisorMu.Unlock()
+
+	status.Countdown = currentCountdown()
+	status.Idle = currentIdleStatus()
+	status.Output = outputHub.stats()
+
+	return status
+}
+
+// GetStatus serves the server lifecycle status as JSON
+func GetStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(currentStatus())
+}
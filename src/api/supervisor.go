@@ -0,0 +1,197 @@
+package api
+
+import (
+	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/discord"
+	"StationeersServerUI/src/lifecycle"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// A process that stays up this long is considered stable and resets the restart backoff
+const stableUptime = 10 * time.Minute
+
+// exitRecord describes how the last server process ended
+type exitRecord struct {
+	Code    int
+	Time    time.Time
+	Reason  string
+	Crashed bool
+}
+
+var (
+	cmdStartedAt       time.Time
+	currentWorld       string
+	cmdDone            chan struct{} // closed by the supervisor once the exit of the current process has been handled
+	stopRequested      bool          // set by StopServer so the supervisor does not treat the exit as a crash
+	supervisorMu       sync.Mutex
+	lastExit           exitRecord
+	consecutiveCrashes int
+	restartTimes       []time.Time // automatic restarts within the last hour, for the circuit breaker
+	restartTimer       *time.Timer
+)
+
+// superviseProcess waits for the server process to exit, records how it ended and restarts it after a crash
+func superviseProcess(c *exec.Cmd, pipes *sync.WaitGroup, done chan struct{}, startedAt time.Time) {
+	// Drain the output before reaping, Wait closes the pipes
+	pipes.Wait()
+	err := c.Wait()
+
+	exitCode := -1
+	reason := "exited normally"
+	if c.ProcessState != nil {
+		exitCode = c.ProcessState.ExitCode()
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		reason = err.Error()
+	} else if c.ProcessState != nil && !c.ProcessState.Success() {
+		reason = c.ProcessState.String()
+	}
+
+	processExited(c, done, startedAt, exitCode, reason)
+}
+
+// processExited records how the server process ended and restarts it unless the exit was intended.
+// done is closed last, a stop waiting on it may start the next process right away.
+func processExited(c *exec.Cmd, done chan struct{}, startedAt time.Time, exitCode int, reason string) {
+	mu.Lock()
+	intended := stopRequested || cmd != c
+	clearProcess(c)
+	mu.Unlock()
+	endCountdown("the server process exited")
+
+	exit := exitRecord{Code: exitCode, Time: time.Now(), Reason: reason, Crashed: !intended}
+	supervisorMu.Lock()
+	lastExit = exit
+	supervisorMu.Unlock()
+	publishProcessExited(exit)
+
+	if intended {
+		lifecycle.Transition(lifecycle.Stopped)
+		fmt.Printf("Server process exited after stop request (exit code %d)\n", exitCode)
+		closeSessionLog()
+		close(done)
+		return
+	}
+
+	crashes.Add(1)
+	lifecycle.Transition(lifecycle.Crashed)
+	notifySupervisor(fmt.Sprintf("💥Server process crashed: %s (exit code %d) after %s uptime.", reason, exitCode, time.Since(startedAt).Round(time.Second)))
+	scheduleRestart(time.Since(startedAt))
+
+	// The crash report above is the last entry of the session log
+	closeSessionLog()
+	close(done)
+}
+
+// clearProcess forgets the given process if it is still the current one, the caller must hold mu
+func clearProcess(c *exec.Cmd) {
+	if cmd != c {
+		return
+	}
+	cmd = nil
+	cmdReattached = false
+	cmdDetached = false
+
+	detachConsole()
+	removeProcessFile()
+}
+
+// scheduleRestart arms the restart timer using exponential backoff, unless the circuit breaker is open
+func scheduleRestart(uptime time.Duration) {
+	supervisorMu.Lock()
+	defer supervisorMu.Unlock()
+
+	if uptime >= stableUptime {
+		consecutiveCrashes = 0
+	}
+	consecutiveCrashes++
+
+	if !config.AutoRestartEnabled {
+		notifySupervisor("⚠️Automatic restart is disabled, server stays down until started manually.")
+		return
+	}
+
+	// Forget restarts older than an hour
+	cutoff := time.Now().Add(-time.Hour)
+	recent := restartTimes[:0]
+	for _, t := range restartTimes {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	restartTimes = recent
+
+	if len(restartTimes) >= config.MaxRestartsPerHour {
+		notifySupervisor(fmt.Sprintf("🛑Server crashed %d times within the last hour, automatic restart suspended. Start the server manually once the issue is resolved.", len(restartTimes)))
+		return
+	}
+
+	delay := restartBackoff(consecutiveCrashes)
+	notifySupervisor(fmt.Sprintf("♻️Restarting server in %s (attempt %d).", delay, consecutiveCrashes))
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		supervisorMu.Lock()
+		if restartTimer != timer {
+			// Cancelled by a manual start or stop in the meantime
+			supervisorMu.Unlock()
+			return
+		}
+		restartTimer = nil
+		restartTimes = append(restartTimes, time.Now())
+		supervisorMu.Unlock()
+
+		err := startServer()
+		if errors.Is(err, errServerRunning) {
+			return
+		}
+		if err != nil {
+			notifySupervisor(fmt.Sprintf("❌Automatic restart failed: %v", err))
+			scheduleRestart(0)
+			return
+		}
+		automaticRestarts.Add(1)
+		notifySupervisor("🕛Server restarted by supervisor.")
+	})
+	restartTimer = timer
+}
+
+// restartBackoff doubles the configured delay for every consecutive crash, capped at the configured maximum
+func restartBackoff(attempt int) time.Duration {
+	delay := time.Duration(config.RestartBackoffSeconds) * time.Second
+	maxDelay := time.Duration(config.RestartBackoffMaxSecs) * time.Second
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// cancelPendingRestart stops a scheduled automatic restart and reports whether one was pending
+func cancelPendingRestart() bool {
+	supervisorMu.Lock()
+	defer supervisorMu.Unlock()
+
+	if restartTimer == nil {
+		return false
+	}
+	restartTimer.Stop()
+	restartTimer = nil
+	return true
+}
+
+// notifySupervisor reports supervisor events on the console, the output stream and the Discord status channel
+func notifySupervisor(message string) {
+	fmt.Println(message)
+	broadcastOutput("[Supervisor] " + message)
+	if config.IsDiscordEnabled {
+		discord.SendMessageToStatusChannel(message)
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"StationeersServerUI/src/lifecycle"
+	"fmt"
+	"os/exec"
+)
+
+// updateServer updates the dedicated server files with SteamCMD, validate also repairs changed files.
+// The server has to be stopped.
+func updateServer(validate bool) error {
+	// Updating the files of a running server is not possible
+	if err := lifecycle.Transition(lifecycle.Updating); err != nil {
+		return err
+	}
+	defer lifecycle.Transition(lifecycle.Stopped)
+
+	appUpdate := "+app_update 600760 -beta public"
+	if validate {
+		appUpdate += " -validate"
+	}
+
+	// PowerShell command to run SteamCMD
+	powerShellScript := `
+		cd C:\SteamCMD
+		.\steamcmd +force_install_dir C:/SteamCMD/Stationeers/ +login anonymous ` + appUpdate + ` +quit
+	`
+
+	// Execute the PowerShell command and wait for it to complete
+	cmd := exec.Command("powershell", "-Command", powerShellScript)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting update command: %v", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("error during update process: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,444 @@
+package api
+
+import (
+	"StationeersServerUI/src/auth"
+	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/lifecycle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// redactedSecret replaces secrets in config responses, sending it back keeps the stored value
+const redactedSecret = "********"
+
+// The /api/v1 handlers answer with JSON only, errors are {"error": "..."} with a matching status code
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+type messageResponse struct {
+	Message string   `json:"message"`
+	Steps   []string `json:"steps,omitempty"`
+}
+
+// v1Methods are the methods of the v1 routes, V1NotFound tries them to tell a wrong method from an unknown path
+var v1Methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// V1NotFound answers unknown /api/v1 routes with 404 and known routes called with the wrong method with 405.
+// The routes are registered with their methods, so the catch-all also receives the wrong methods.
+func V1NotFound(w http.ResponseWriter, r *http.Request) {
+	var allowed []string
+	for _, method := range v1Methods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := http.DefaultServeMux.Handler(probe); pattern != "" && pattern != "/api/v1/" {
+			allowed = append(allowed, method)
+		}
+	}
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeJSONError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s is not allowed for %s, use %s", r.Method, r.URL.Path, strings.Join(allowed, " or ")))
+		return
+	}
+	writeJSONError(w, http.StatusNotFound, fmt.Sprintf("No endpoint for %s %s, see /api/v1/openapi.json", r.Method, r.URL.Path))
+}
+
+// V1GetStatus serves the lifecycle status of the dedicated server
+func V1GetStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, currentStatus())
+}
+
+// V1StartServer launches the dedicated server, the answer is sent once the process is running but before it is ready
+func V1StartServer(w http.ResponseWriter, r *http.Request) {
+	err := startServer()
+	var transitionErr *lifecycle.TransitionError
+	if errors.Is(err, errServerRunning) || errors.As(err, &transitionErr) {
+		writeJSONError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Error starting server: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusAccepted, currentStatus())
+}
+
+// V1StopServer saves and stops the dedicated server and returns the shutdown steps
+func V1StopServer(w http.ResponseWriter, r *http.Request) {
+	result, err := localServer{}.Stop()
+	if errors.Is(err, errServerNotRunning) {
+		if result.RestartCancelled {
+			writeJSON(w, http.StatusOK, messageResponse{Message: "Server is not running. Pending automatic restart cancelled."})
+			return
+		}
+		writeJSONError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Error stopping server: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, messageResponse{Message: "Server stopped.", Steps: result.Steps})
+}
+
+// V1RestartServer stops the dedicated server if it is running and starts it again
+func V1RestartServer(w http.ResponseWriter, r *http.Request) {
+	result, err := localServer{}.Restart()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Error restarting server: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusAccepted, messageResponse{Message: "Server restarted.", Steps: result.Steps})
+}
+
+// V1ListBackups serves the restorable backups, newest first
+func V1ListBackups(w http.ResponseWriter, r *http.Request) {
+	backups, err := listBackups()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, backups)
+}
+
+// V1RestoreBackup restores the backup with the index from the path, the server must be stopped
+func V1RestoreBackup(w http.ResponseWriter, r *http.Request) {
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil || index < 0 {
+		writeJSONError(w, http.StatusBadRequest, "Invalid backup index")
+		return
+	}
+
+	err = restoreBackup(index)
+	switch {
+	case errors.Is(err, errCannotRestore):
+		writeJSONError(w, http.StatusConflict, fmt.Sprintf("%v. Stop the server first.", err))
+	case errors.Is(err, errBackupNotFound):
+		writeJSONError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, messageResponse{Message: fmt.Sprintf("Backup %d restored successfully.", index)})
+	}
+}
+
+type gameConfig struct {
+	SaveFileName string            `json:"saveFileName"`
+	ExePath      string            `json:"exePath"`
+	Settings     map[string]string `json:"settings"`
+}
+
+type configResponse struct {
+	Game       gameConfig     `json:"game"`
+	Controller *config.Config `json:"controller"`
+}
+
+// parseSettings splits the settings string of the game config into ordered names and their values
+func parseSettings(settings string) ([]string, map[string]string) {
+	var names []string
+	values := make(map[string]string)
+	fields := strings.Fields(settings)
+	for i := 0; i < len(fields)-1; i += 2 {
+		if _, seen := values[fields[i]]; !seen {
+			names = append(names, fields[i])
+		}
+		values[fields[i]] = fields[i+1]
+	}
+	return names, values
+}
+
+func loadConfigResponse() (configResponse, error) {
+	xmlConfig, err := loadConfig()
+	if err != nil {
+		return configResponse{}, err
+	}
+	jsonConfig, err := loadConfigJSON()
+	if err != nil {
+		return configResponse{}, err
+	}
+	if jsonConfig.DiscordToken != "" {
+		jsonConfig.DiscordToken = redactedSecret
+	}
+	for i := range jsonConfig.Webhooks {
+		if jsonConfig.Webhooks[i].Secret != "" {
+			jsonConfig.Webhooks[i].Secret = redactedSecret
+		}
+	}
+
+	_, settings := parseSettings(xmlConfig.Server.Settings)
+	return configResponse{
+		Game:       gameConfig{SaveFileName: xmlConfig.SaveFileName, ExePath: xmlConfig.Server.ExePath, Settings: settings},
+		Controller: jsonConfig,
+	}, nil
+}
+
+// V1GetConfig serves the game config and the controller config, the Discord token and webhook secrets are redacted
+func V1GetConfig(w http.ResponseWriter, r *http.Request) {
+	response, err := loadConfigResponse()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// V1PatchGameConfig changes the save name and individual launch settings, an empty value removes a setting
+func V1PatchGameConfig(w http.ResponseWriter, r *http.Request) {
+	var patch struct {
+		SaveFileName *string           `json:"saveFileName"`
+		Settings     map[string]string `json:"settings"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON body: %v", err))
+		return
+	}
+
+	xmlConfig, err := loadConfig()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if patch.SaveFileName != nil {
+		if strings.TrimSpace(*patch.SaveFileName) == "" {
+			writeJSONError(w, http.StatusBadRequest, "saveFileName must not be empty")
+			return
+		}
+		xmlConfig.SaveFileName = strings.TrimSpace(*patch.SaveFileName)
+	}
+
+	// Keep the order of existing settings and append new ones sorted by name
+	names, values := parseSettings(xmlConfig.Server.Settings)
+	var added []string
+	for name, value := range patch.Settings {
+		if name == "" || strings.ContainsAny(name+value, " \t\n") {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid setting %q, names and values may not contain spaces", name))
+			return
+		}
+		if _, exists := values[name]; !exists && value != "" {
+			added = append(added, name)
+		}
+		values[name] = value
+	}
+	sort.Strings(added)
+
+	var settings []string
+	for _, name := range append(names, added...) {
+		if values[name] != "" {
+			settings = append(settings, name, values[name])
+		}
+	}
+	xmlConfig.Server.Settings = strings.Join(settings, " ")
+
+	if err := saveGameConfig(*xmlConfig); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	fmt.Printf("Game config changed by %s\n", auth.Username(r))
+
+	response, err := loadConfigResponse()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// V1PatchControllerConfig changes the fields of config.json present in the body, they apply on the next controller start
+func V1PatchControllerConfig(w http.ResponseWriter, r *http.Request) {
+	jsonConfig, err := loadConfigJSON()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	storedToken := jsonConfig.DiscordToken
+	storedSecrets := make(map[string]string)
+	for _, hook := range jsonConfig.Webhooks {
+		storedSecrets[hook.URL] = hook.Secret
+	}
+
+	// Decoding onto the stored config only replaces the fields present in the body
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(jsonConfig); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON body: %v", err))
+		return
+	}
+	if jsonConfig.DiscordToken == redactedSecret {
+		jsonConfig.DiscordToken = storedToken
+	}
+	for i, hook := range jsonConfig.Webhooks {
+		if hook.Secret == redactedSecret {
+			jsonConfig.Webhooks[i].Secret = storedSecrets[hook.URL]
+		}
+	}
+
+	if err := saveConfigJSON(jsonConfig); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	fmt.Printf("Controller config changed by %s\n", auth.Username(r))
+
+	response, err := loadConfigResponse()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+type playerInfo struct {
+	SteamID string `json:"steamId"`
+	Name    string `json:"name"`
+}
+
+// V1ListPlayers serves the players currently connected to the server
+func V1ListPlayers(w http.ResponseWriter, r *http.Request) {
+	players := []playerInfo{}
+	config.ConnectedPlayersMu.Lock()
+	for steamID, name := range config.ConnectedPlayers {
+		players = append(players, playerInfo{SteamID: steamID, Name: name})
+	}
+	config.ConnectedPlayersMu.Unlock()
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].Name < players[j].Name
+	})
+	writeJSON(w, http.StatusOK, players)
+}
+
+// V1ListBans serves the banned SteamIDs
+func V1ListBans(w http.ResponseWriter, r *http.Request) {
+	ids, err := readBlacklist()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Error reading blacklist: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, ids)
+}
+
+// V1BanPlayer bans the SteamID from the path, banning an already banned player is not an error
+func V1BanPlayer(w http.ResponseWriter, r *http.Request) {
+	steamID := r.PathValue("steamId")
+	if !validSteamID(steamID) {
+		writeJSONError(w, http.StatusBadRequest, "Invalid SteamID, expected the numeric SteamID64")
+		return
+	}
+
+	changed, err := setBanned(steamID, true)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Error updating blacklist: %v", err))
+		return
+	}
+	if !changed {
+		writeJSON(w, http.StatusOK, messageResponse{Message: fmt.Sprintf("SteamID %s is already banned.", steamID)})
+		return
+	}
+	writeJSON(w, http.StatusCreated, messageResponse{Message: fmt.Sprintf("SteamID %s has been banned.", steamID)})
+}
+
+// V1UnbanPlayer removes the SteamID from the path from the blacklist
+func V1UnbanPlayer(w http.ResponseWriter, r *http.Request) {
+	steamID := r.PathValue("steamId")
+	if !validSteamID(steamID) {
+		writeJSONError(w, http.StatusBadRequest, "Invalid SteamID, expected the numeric SteamID64")
+		return
+	}
+
+	changed, err := setBanned(steamID, false)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Error updating blacklist: %v", err))
+		return
+	}
+	if !changed {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("SteamID %s is not banned.", steamID))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// V1ListLogs serves the server log files, newest first
+func V1ListLogs(w http.ResponseWriter, r *http.Request) {
+	files, err := listLogFiles()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Error reading logs directory: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, files)
+}
+
+// V1SearchLogs searches the server logs, it takes the same parameters as /api/logs/search
+func V1SearchLogs(w http.ResponseWriter, r *http.Request) {
+	q, err := parseSearchQuery(r.URL.Query())
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := searchLogs(q)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("Error searching logs: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// V1DownloadLog serves the log file named in the path
+func V1DownloadLog(w http.ResponseWriter, r *http.Request) {
+	path, err := logFilePath(r.PathValue("name"))
+	if errors.Is(err, errInvalidLogName) {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	serveLogDownload(w, r, path)
+}
+
+// V1SendConsoleCommand sends {"command": "..."} to the server console on behalf of the caller
+func V1SendConsoleCommand(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Command string `json:"command"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	err := SendConsoleCommand("api", auth.Username(r), request.Command)
+	switch {
+	case errors.Is(err, errCommandNotAllowed):
+		writeJSONError(w, http.StatusForbidden, fmt.Sprintf("Command rejected: %v", err))
+	case errors.Is(err, errServerNotRunning):
+		writeJSONError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Error sending command: %v", err))
+	default:
+		writeJSON(w, http.StatusAccepted, messageResponse{Message: fmt.Sprintf("Command sent: %s", strings.TrimSpace(request.Command))})
+	}
+}
+
+// V1GetConsoleAudit serves the most recent console commands
+func V1GetConsoleAudit(w http.ResponseWriter, r *http.Request) {
+	auditMu.Lock()
+	entries := make([]consoleAuditEntry, len(recentAudit))
+	copy(entries, recentAudit)
+	auditMu.Unlock()
+
+	writeJSON(w, http.StatusOK, entries)
+}
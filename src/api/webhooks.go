@@ -0,0 +1,99 @@
+package api
+
+import (
+	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/events"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	webhookQueueSize = 256
+	webhookAttempts  = 3
+)
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// StartWebhooks posts server events to the webhooks from config.json, each webhook gets its own
+// subscription so a slow receiver only delays itself
+func StartWebhooks() {
+	for _, hook := range config.Webhooks {
+		if hook.URL == "" {
+			continue
+		}
+		wanted := make(map[events.Type]bool)
+		for _, name := range hook.Events {
+			if !events.ValidType(events.Type(name)) {
+				fmt.Printf("⚠️Webhook %s: unknown event type %q, valid types are %v\n", hook.URL, name, events.Types)
+				continue
+			}
+			wanted[events.Type(name)] = true
+		}
+		if len(hook.Events) > 0 && len(wanted) == 0 {
+			fmt.Printf("⚠️Webhook %s has no valid event types, it is disabled\n", hook.URL)
+			continue
+		}
+
+		sub := events.Subscribe("webhook "+hook.URL, webhookQueueSize)
+		go func(hook config.Webhook) {
+			for e := range sub.Events() {
+				if len(wanted) > 0 && !wanted[e.Type] {
+					continue
+				}
+				if err := deliverWebhook(hook, e); err != nil {
+					fmt.Printf("Error delivering %s event to webhook %s: %v\n", e.Type, hook.URL, err)
+				}
+			}
+		}(hook)
+		fmt.Printf("Sending server events to webhook %s\n", hook.URL)
+	}
+}
+
+// deliverWebhook posts one event, retrying with a growing delay if the receiver fails
+func deliverWebhook(hook config.Webhook, e events.Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(time.Duration(attempt-1) * 2 * time.Second)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-SSUI-Event", string(e.Type))
+		if hook.Secret != "" {
+			mac := hmac.New(sha256.New, []byte(hook.Secret))
+			mac.Write(body)
+			req.Header.Set("X-SSUI-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		}
+
+		resp, err := webhookClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("receiver answered %s", resp.Status)
+		if resp.StatusCode < 500 {
+			// The receiver rejected the event, sending it again will not help
+			return lastErr
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HandleLogin serves the login page and checks submitted credentials
+func HandleLogin(w http.ResponseWriter, r *http.Request) {
+	if !HasUsers() {
+		http.Redirect(w, r, "/setup", http.StatusSeeOther)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		http.ServeFile(w, r, "./UIMod/login.html")
+	case http.MethodPost:
+		user, ok := checkPassword(r.FormValue("username"), r.FormValue("password"))
+		if !ok {
+			fmt.Printf("Failed login for %q from %s\n", r.FormValue("username"), r.RemoteAddr)
+			time.Sleep(time.Second) // slow down password guessing
+			http.Redirect(w, r, "/login?error="+url.QueryEscape("Invalid username or password."), http.StatusSeeOther)
+			return
+		}
+		startSession(w, user.Username)
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	default:
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleLogout ends the session of the current user
+func HandleLogout(w http.ResponseWriter, r *http.Request) {
+	endSession(w, r)
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// HandleSetup creates the first admin account, it is only available while no account exists
+func HandleSetup(w http.ResponseWriter, r *http.Request) {
+	if HasUsers() {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		http.ServeFile(w, r, "./UIMod/setup.html")
+	case http.MethodPost:
+		if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(r.FormValue("setupCode"))), []byte(setupCode)) != 1 {
+			time.Sleep(time.Second)
+			http.Redirect(w, r, "/setup?error="+url.QueryEscape("Invalid setup code, see the controller console."), http.StatusSeeOther)
+			return
+		}
+
+		username := strings.TrimSpace(r.FormValue("username"))
+		if err := createUser(username, r.FormValue("password"), RoleAdmin); err != nil {
+			http.Redirect(w, r, "/setup?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+			return
+		}
+		fmt.Println("Created admin account", username)
+		startSession(w, username)
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+	default:
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+	}
+}
+
+// ServeUsersPage serves the account page, every user can change their password there and admins manage the accounts
+func ServeUsersPage(w http.ResponseWriter, r *http.Request) {
+	http.ServeFile(w, r, "./UIMod/users.html")
+}
+
+type userInfo struct {
+	Username string    `json:"username"`
+	Role     Role      `json:"role"`
+	Created  time.Time `json:"created"`
+}
+
+type userRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     Role   `json:"role"`
+}
+
+// HandleUsers lists (GET), creates (POST), changes the role of (PUT) and deletes (DELETE ?username=) user accounts
+func HandleUsers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		list := []userInfo{}
+		for _, user := range listUsers() {
+			list = append(list, userInfo{Username: user.Username, Role: user.Role, Created: user.Created})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Current string     `json:"current"`
+			Users   []userInfo `json:"users"`
+		}{Username(r), list})
+
+	case http.MethodPost:
+		var request userRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if request.Role == "" {
+			request.Role = RoleViewer
+		}
+		err := createUser(strings.TrimSpace(request.Username), request.Password, request.Role)
+		if errors.Is(err, errUserExists) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Printf("User %s created account %s (%s)\n", Username(r), request.Username, request.Role)
+		fmt.Fprintf(w, "User %s created with role %s.", request.Username, request.Role)
+
+	case http.MethodPut:
+		var request userRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if err := setRole(request.Username, request.Role); err != nil {
+			writeUserError(w, err)
+			return
+		}
+		endSessionsOf(request.Username)
+		capTokensOf(request.Username, request.Role)
+		fmt.Printf("User %s changed the role of %s to %s\n", Username(r), request.Username, request.Role)
+		fmt.Fprintf(w, "User %s is now %s.", request.Username, request.Role)
+
+	case http.MethodDelete:
+		username := r.URL.Query().Get("username")
+		if err := deleteUser(username); err != nil {
+			writeUserError(w, err)
+			return
+		}
+		endSessionsOf(username)
+		revokeTokensOf(username)
+		fmt.Printf("User %s deleted account %s\n", Username(r), username)
+		fmt.Fprintf(w, "User %s deleted.", username)
+
+	default:
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeUserError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, errLastAdmin):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+// HandlePassword changes the password of the current user after checking the current one, and logs them out everywhere else
+func HandlePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		CurrentPassword string `json:"currentPassword"`
+		Password        string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	// A stolen session alone must not be enough to take over the account
+	username := Username(r)
+	if _, ok := checkPassword(username, request.CurrentPassword); !ok {
+		fmt.Printf("Failed password change for %q from %s\n", username, r.RemoteAddr)
+		http.Error(w, "The current password is incorrect.", http.StatusForbidden)
+		return
+	}
+	if err := setPassword(username, request.Password); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	endSessionsOf(username)
+	startSession(w, username)
+	fmt.Fprint(w, "Password changed.")
+}
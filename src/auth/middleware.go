@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type contextKey int
+
+const principalContextKey contextKey = iota
+
+// principal is the caller of a request, either a user or an API token
+type principal struct {
+	username string
+	token    *APIToken // nil unless authenticated with an API token
+}
+
+var setupCode string // one-time code printed to the console while no account exists
+
+// publicPaths are reachable without logging in
+var publicPaths = map[string]bool{
+	"/login":                  true,
+	"/setup":                  true,
+	"/static/style.css":       true,
+	"/static/stationeers.png": true,
+}
+
+// Init loads the user accounts and prepares the first-run bootstrap if there are none
+func Init() error {
+	if err := loadUsers(); err != nil {
+		return err
+	}
+	if err := loadTokens(); err != nil {
+		return err
+	}
+	if !HasUsers() {
+		setupCode = randomToken(4)
+		fmt.Println("No user accounts exist yet. Open /setup in the web UI and use this setup code to create the admin account:", setupCode)
+	}
+	return nil
+}
+
+// Middleware requires a logged in user or an API token for every request except the login pages
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		caller, ok := authenticate(r)
+		if !ok {
+			if !HasUsers() {
+				deny(w, r, "/setup", "No user accounts exist yet, complete the setup at /setup first.")
+				return
+			}
+			deny(w, r, "/login", "Login required.")
+			return
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), principalContextKey, caller))
+		// The profiler is registered by net/http/pprof itself, so it is guarded here
+		if strings.HasPrefix(r.URL.Path, "/debug/") && !Can(r, PermDebug) {
+			forbidden(w, r, PermDebug)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate identifies the caller by bearer token or session cookie
+func authenticate(r *http.Request) (principal, bool) {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		secret := strings.TrimPrefix(header, "Bearer ")
+		if token, ok := lookupToken(secret); ok {
+			return principal{username: "token:" + token.Name, token: token}, true
+		}
+		return principal{}, false
+	}
+
+	username, ok := sessionUser(r)
+	return principal{username: username}, ok
+}
+
+func callerOf(r *http.Request) principal {
+	caller, _ := r.Context().Value(principalContextKey).(principal)
+	return caller
+}
+
+// deny redirects browsers to the given page and answers API clients with 401
+func deny(w http.ResponseWriter, r *http.Request, page, message string) {
+	if r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html") {
+		http.Redirect(w, r, page, http.StatusSeeOther)
+		return
+	}
+	writeError(w, r, http.StatusUnauthorized, message)
+}
+
+// writeError answers in JSON on the versioned API and in plain text everywhere else
+func writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	if strings.HasPrefix(r.URL.Path, "/api/v1/") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": message})
+		return
+	}
+	http.Error(w, message, status)
+}
+
+// Username returns the authenticated user of a request that passed the middleware, API tokens are named "token:<name>"
+func Username(r *http.Request) string {
+	return callerOf(r).username
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Role decides which actions an account may perform
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"   // watch status, output, logs and backups
+	RoleOperator Role = "operator" // additionally start and stop the server, send console commands and ban players
+	RoleAdmin    Role = "admin"    // additionally restore backups, edit the config, manage accounts and tokens and use the profiler
+)
+
+// Permission is an action that is checked before a handler runs
+type Permission string
+
+const (
+	PermViewServer     Permission = "server:view"
+	PermWakeServer     Permission = "server:wake"
+	PermControlServer  Permission = "server:control"
+	PermManagePlayers  Permission = "players:ban"
+	PermRestoreBackups Permission = "backups:restore"
+	PermEditConfig     Permission = "config:edit"
+	PermManageUsers    Permission = "users:manage"
+	PermManageTokens   Permission = "tokens:manage"
+	PermManageSchedule Permission = "schedule:manage"
+	PermDebug          Permission = "debug"
+)
+
+// permissionActions describe the permissions in error messages
+var permissionActions = map[Permission]string{
+	PermViewServer:     "view the server",
+	PermWakeServer:     "wake the server after it was stopped for being idle",
+	PermControlServer:  "start, stop or send commands to the server",
+	PermManagePlayers:  "ban or unban players",
+	PermRestoreBackups: "restore backups",
+	PermEditConfig:     "view or change the configuration",
+	PermManageUsers:    "manage user accounts",
+	PermManageTokens:   "manage API tokens",
+	PermManageSchedule: "change scheduled tasks",
+	PermDebug:          "use the debug endpoints",
+}
+
+var rolePermissions = map[Role][]Permission{
+	RoleViewer:   {PermViewServer, PermWakeServer},
+	RoleOperator: {PermViewServer, PermWakeServer, PermControlServer, PermManagePlayers, PermManageSchedule},
+	RoleAdmin:    {PermViewServer, PermWakeServer, PermControlServer, PermManagePlayers, PermManageSchedule, PermRestoreBackups, PermEditConfig, PermManageUsers, PermManageTokens, PermDebug},
+}
+
+func validRole(role Role) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Allows reports whether the role grants the permission
+func (role Role) Allows(perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// UserRole returns the role of the authenticated user of a request, API tokens have none
+func UserRole(r *http.Request) Role {
+	caller := callerOf(r)
+	if caller.token != nil {
+		return ""
+	}
+
+	usersMu.Lock()
+	defer usersMu.Unlock()
+	return users[strings.ToLower(caller.username)].Role
+}
+
+// Can reports whether the caller of a request has the permission, through their role or the scopes of their API token
+func Can(r *http.Request, perm Permission) bool {
+	if token := callerOf(r).token; token != nil {
+		return token.Allows(perm)
+	}
+	return UserRole(r).Allows(perm)
+}
+
+// permissions lists what the caller of a request may do
+func permissions(r *http.Request) []Permission {
+	list := []Permission{}
+	for _, perm := range rolePermissions[RoleAdmin] {
+		if Can(r, perm) {
+			list = append(list, perm)
+		}
+	}
+	return list
+}
+
+// Require wraps a handler so it only runs for users with the permission, everyone else gets 403
+func Require(perm Permission, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !Can(r, perm) {
+			forbidden(w, r, perm)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func forbidden(w http.ResponseWriter, r *http.Request, perm Permission) {
+	fmt.Printf("Denied %s %s for %s, requires %s\n", r.Method, r.URL.Path, Username(r), perm)
+
+	if token := callerOf(r).token; token != nil {
+		for scope, perms := range scopePermissions {
+			for _, p := range perms {
+				if p == perm {
+					writeError(w, r, http.StatusForbidden, fmt.Sprintf("Permission denied: API tokens need the %s scope to %s.", scope, permissionActions[perm]))
+					return
+				}
+			}
+		}
+		writeError(w, r, http.StatusForbidden, fmt.Sprintf("Permission denied: API tokens are not allowed to %s, log in as an admin instead.", permissionActions[perm]))
+		return
+	}
+
+	writeError(w, r, http.StatusForbidden, fmt.Sprintf("Permission denied: the %s role is not allowed to %s. Ask an admin for a role with the %s permission.",
+		UserRole(r), permissionActions[perm], perm))
+}
+
+// HandleMe returns the logged in user with role and permissions, the UI uses it to show what the user may do
+func HandleMe(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Username    string       `json:"username"`
+		Role        Role         `json:"role,omitempty"`
+		Permissions []Permission `json:"permissions"`
+	}{Username(r), UserRole(r), permissions(r)})
+}
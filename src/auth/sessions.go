@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sessionCookieName = "ssui_session"
+	sessionLifetime   = 12 * time.Hour // sliding, every authenticated request extends the session
+)
+
+type session struct {
+	username string
+	expires  time.Time
+}
+
+var (
+	sessionsMu sync.Mutex
+	sessions   = make(map[string]*session)
+)
+
+// randomToken returns a hex encoded random value of n bytes
+func randomToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("auth: unable to read random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// startSession creates a session for the user and sets its cookie
+func startSession(w http.ResponseWriter, username string) {
+	token := randomToken(32)
+
+	sessionsMu.Lock()
+	sessions[token] = &session{username: username, expires: time.Now().Add(sessionLifetime)}
+	sessionsMu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// sessionUser returns the user of the request's session cookie, if the session is valid
+func sessionUser(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return "", false
+	}
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	s, ok := sessions[cookie.Value]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(s.expires) {
+		delete(sessions, cookie.Value)
+		return "", false
+	}
+	s.expires = time.Now().Add(sessionLifetime)
+	return s.username, true
+}
+
+// endSession removes the request's session and clears its cookie
+func endSession(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookieName); err == nil {
+		sessionsMu.Lock()
+		delete(sessions, cookie.Value)
+		sessionsMu.Unlock()
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// endSessionsOf logs a user out everywhere, used when the account is deleted or its role or password changes
+func endSessionsOf(username string) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+
+	for token, s := range sessions {
+		if strings.EqualFold(s.username, username) {
+			delete(sessions, token)
+		}
+	}
+}
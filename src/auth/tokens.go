@@ -0,0 +1,298 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tokensFilePath   = "./UIMod/tokens.json"
+	tokenPrefix      = "ssui_"
+	lastUsedInterval = time.Minute // how often the last use of a token is written to disk
+)
+
+// Scope limits what an API token may do
+type Scope string
+
+const (
+	ScopeStatusRead     Scope = "status:read"
+	ScopeProcessControl Scope = "process:control"
+	ScopeBackupsManage  Scope = "backups:manage"
+	ScopeConfigManage   Scope = "config:manage"
+)
+
+var scopePermissions = map[Scope][]Permission{
+	ScopeStatusRead:     {PermViewServer},
+	ScopeProcessControl: {PermWakeServer, PermControlServer, PermManagePlayers, PermManageSchedule},
+	ScopeBackupsManage:  {PermRestoreBackups},
+	ScopeConfigManage:   {PermEditConfig},
+}
+
+var errInvalidScope = errors.New("scopes must be status:read, process:control, backups:manage or config:manage")
+
+// APIToken is a long-lived credential for scripts, only the SHA-256 hash of the secret is stored
+type APIToken struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Hash      string    `json:"hash"`
+	Hint      string    `json:"hint"` // first characters of the secret so users can tell tokens apart
+	Scopes    []Scope   `json:"scopes"`
+	CreatedBy string    `json:"createdBy"`
+	Created   time.Time `json:"created"`
+	LastUsed  time.Time `json:"lastUsed,omitempty"`
+}
+
+// Allows reports whether one of the token's scopes grants the permission
+func (t *APIToken) Allows(perm Permission) bool {
+	for _, scope := range t.Scopes {
+		for _, p := range scopePermissions[scope] {
+			if p == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var (
+	tokensMu sync.Mutex
+	tokens   map[string]*APIToken // by hash
+)
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadTokens reads the API tokens from disk, a missing file means no tokens exist
+func loadTokens() error {
+	tokensMu.Lock()
+	defer tokensMu.Unlock()
+
+	tokens = make(map[string]*APIToken)
+	data, err := os.ReadFile(tokensFilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading tokens file: %v", err)
+	}
+
+	var list []*APIToken
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("error parsing tokens file: %v", err)
+	}
+	for _, token := range list {
+		tokens[token.Hash] = token
+	}
+	return nil
+}
+
+// saveTokensLocked writes the API tokens to disk, the caller must hold tokensMu
+func saveTokensLocked() error {
+	data, err := json.MarshalIndent(listTokensLocked(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(tokensFilePath, data, 0600)
+}
+
+func listTokensLocked() []APIToken {
+	list := make([]APIToken, 0, len(tokens))
+	for _, token := range tokens {
+		list = append(list, *token)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Created.Before(list[j].Created)
+	})
+	return list
+}
+
+// createToken stores a new token and returns its secret, which is not retrievable later
+func createToken(name string, scopes []Scope, createdBy string) (string, APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 64 {
+		return "", APIToken{}, errors.New("token name must be between 1 and 64 characters")
+	}
+	if len(scopes) == 0 {
+		return "", APIToken{}, errInvalidScope
+	}
+	for _, scope := range scopes {
+		if _, ok := scopePermissions[scope]; !ok {
+			return "", APIToken{}, errInvalidScope
+		}
+	}
+
+	secret := tokenPrefix + randomToken(24)
+	token := &APIToken{
+		ID:        randomToken(8),
+		Name:      name,
+		Hash:      hashToken(secret),
+		Hint:      secret[:len(tokenPrefix)+6],
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		Created:   time.Now(),
+	}
+
+	tokensMu.Lock()
+	defer tokensMu.Unlock()
+	tokens[token.Hash] = token
+	return secret, *token, saveTokensLocked()
+}
+
+// revokeToken deletes the token with the given id
+func revokeToken(id string) error {
+	tokensMu.Lock()
+	defer tokensMu.Unlock()
+
+	for hash, token := range tokens {
+		if token.ID == id {
+			delete(tokens, hash)
+			return saveTokensLocked()
+		}
+	}
+	return errors.New("token not found")
+}
+
+// revokeTokensOf deletes all tokens created by a user, used when the account is deleted
+func revokeTokensOf(username string) {
+	tokensMu.Lock()
+	defer tokensMu.Unlock()
+
+	changed := false
+	for hash, token := range tokens {
+		if strings.EqualFold(token.CreatedBy, username) {
+			delete(tokens, hash)
+			changed = true
+		}
+	}
+	if changed {
+		if err := saveTokensLocked(); err != nil {
+			fmt.Println("Error saving tokens file:", err)
+		}
+	}
+}
+
+// capTokensOf drops the scopes a user's new role does not fully grant from their tokens,
+// tokens left without scopes are revoked
+func capTokensOf(username string, role Role) {
+	tokensMu.Lock()
+	defer tokensMu.Unlock()
+
+	changed := false
+	for hash, token := range tokens {
+		if !strings.EqualFold(token.CreatedBy, username) {
+			continue
+		}
+		kept := []Scope{}
+		for _, scope := range token.Scopes {
+			if roleGrantsScope(role, scope) {
+				kept = append(kept, scope)
+			}
+		}
+		if len(kept) == len(token.Scopes) {
+			continue
+		}
+		changed = true
+		if len(kept) == 0 {
+			fmt.Printf("Revoked API token %s of %s, the role %s grants none of its scopes\n", token.Name, username, role)
+			delete(tokens, hash)
+			continue
+		}
+		fmt.Printf("Limited API token %s of %s to %v for the role %s\n", token.Name, username, kept, role)
+		token.Scopes = kept
+	}
+	if changed {
+		if err := saveTokensLocked(); err != nil {
+			fmt.Println("Error saving tokens file:", err)
+		}
+	}
+}
+
+// roleGrantsScope reports whether the role has every permission of the scope
+func roleGrantsScope(role Role, scope Scope) bool {
+	for _, perm := range scopePermissions[scope] {
+		if !role.Allows(perm) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupToken returns the token matching the secret and records its use
+func lookupToken(secret string) (*APIToken, bool) {
+	tokensMu.Lock()
+	defer tokensMu.Unlock()
+
+	token, ok := tokens[hashToken(secret)]
+	if !ok {
+		return nil, false
+	}
+	if time.Since(token.LastUsed) > lastUsedInterval {
+		token.LastUsed = time.Now()
+		if err := saveTokensLocked(); err != nil {
+			fmt.Println("Error saving tokens file:", err)
+		}
+	}
+	copied := *token
+	return &copied, true
+}
+
+// ServeTokensPage serves the API token management page
+func ServeTokensPage(w http.ResponseWriter, r *http.Request) {
+	http.ServeFile(w, r, "./UIMod/tokens.html")
+}
+
+// HandleTokens lists (GET), creates (POST) and revokes (DELETE ?id=) API tokens
+func HandleTokens(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		tokensMu.Lock()
+		list := listTokensLocked()
+		tokensMu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+
+	case http.MethodPost:
+		var request struct {
+			Name   string  `json:"name"`
+			Scopes []Scope `json:"scopes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		secret, token, err := createToken(request.Name, request.Scopes, Username(r))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Printf("User %s created API token %s with scopes %v\n", Username(r), token.Name, token.Scopes)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Token string   `json:"token"`
+			Info  APIToken `json:"info"`
+		}{secret, token})
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		if err := revokeToken(id); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		fmt.Printf("User %s revoked API token %s\n", Username(r), id)
+		fmt.Fprint(w, "Token revoked.")
+
+	default:
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,241 @@
+// Package auth provides local user accounts, login sessions and the middleware that protects the web UI and API.
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	usersFilePath     = "./UIMod/users.json"
+	minPasswordLength = 8
+)
+
+var (
+	errUserExists      = errors.New("user already exists")
+	errUserNotFound    = errors.New("user not found")
+	errInvalidUsername = errors.New("username may only contain letters, digits, dots, dashes and underscores")
+	errPasswordTooWeak = fmt.Errorf("password must be at least %d characters long", minPasswordLength)
+	errInvalidRole     = errors.New("role must be viewer, operator or admin")
+	errLastAdmin       = errors.New("the last admin account cannot be removed or demoted")
+)
+
+// User is a local account, only the bcrypt hash of the password is stored
+type User struct {
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"passwordHash"`
+	Role         Role      `json:"role"`
+	Created      time.Time `json:"created"`
+}
+
+var (
+	usersMu      sync.Mutex
+	users        map[string]User
+	dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+)
+
+// loadUsers reads the user accounts from disk, a missing file means no accounts exist yet
+func loadUsers() error {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+
+	users = make(map[string]User)
+	data, err := os.ReadFile(usersFilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading users file: %v", err)
+	}
+
+	var list []User
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("error parsing users file: %v", err)
+	}
+	for _, user := range list {
+		if user.Role == "" {
+			// Accounts created before roles existed had full access
+			user.Role = RoleAdmin
+		}
+		users[strings.ToLower(user.Username)] = user
+	}
+	return nil
+}
+
+// saveUsersLocked writes the user accounts to disk, the caller must hold usersMu
+func saveUsersLocked() error {
+	list := make([]User, 0, len(users))
+	for _, user := range users {
+		list = append(list, user)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Username < list[j].Username
+	})
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(usersFilePath, data, 0600)
+}
+
+// HasUsers reports whether at least one account exists
+func HasUsers() bool {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+	return len(users) > 0
+}
+
+func validUsername(username string) bool {
+	if username == "" || len(username) > 64 {
+		return false
+	}
+	for _, r := range username {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// createUser adds a new account with a hashed password
+func createUser(username, password string, role Role) error {
+	if !validUsername(username) {
+		return errInvalidUsername
+	}
+	if !validRole(role) {
+		return errInvalidRole
+	}
+	if len(password) < minPasswordLength {
+		return errPasswordTooWeak
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	usersMu.Lock()
+	defer usersMu.Unlock()
+
+	key := strings.ToLower(username)
+	if _, exists := users[key]; exists {
+		return errUserExists
+	}
+	users[key] = User{Username: username, PasswordHash: string(hash), Role: role, Created: time.Now()}
+	return saveUsersLocked()
+}
+
+// deleteUser removes an account
+func deleteUser(username string) error {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+
+	key := strings.ToLower(username)
+	user, exists := users[key]
+	if !exists {
+		return errUserNotFound
+	}
+	if user.Role == RoleAdmin && countAdminsLocked() == 1 {
+		return errLastAdmin
+	}
+	delete(users, key)
+	return saveUsersLocked()
+}
+
+// setRole changes the role of an existing account
+func setRole(username string, role Role) error {
+	if !validRole(role) {
+		return errInvalidRole
+	}
+
+	usersMu.Lock()
+	defer usersMu.Unlock()
+
+	key := strings.ToLower(username)
+	user, exists := users[key]
+	if !exists {
+		return errUserNotFound
+	}
+	if user.Role == RoleAdmin && role != RoleAdmin && countAdminsLocked() == 1 {
+		return errLastAdmin
+	}
+	user.Role = role
+	users[key] = user
+	return saveUsersLocked()
+}
+
+// countAdminsLocked counts the admin accounts, the caller must hold usersMu
+func countAdminsLocked() int {
+	count := 0
+	for _, user := range users {
+		if user.Role == RoleAdmin {
+			count++
+		}
+	}
+	return count
+}
+
+// setPassword replaces the password of an existing account
+func setPassword(username, password string) error {
+	if len(password) < minPasswordLength {
+		return errPasswordTooWeak
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	usersMu.Lock()
+	defer usersMu.Unlock()
+
+	key := strings.ToLower(username)
+	user, exists := users[key]
+	if !exists {
+		return errUserNotFound
+	}
+	user.PasswordHash = string(hash)
+	users[key] = user
+	return saveUsersLocked()
+}
+
+// checkPassword returns the account if the password matches
+func checkPassword(username, password string) (User, bool) {
+	usersMu.Lock()
+	user, exists := users[strings.ToLower(username)]
+	usersMu.Unlock()
+
+	if !exists {
+		// Spend the same time as for a wrong password so usernames cannot be probed
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return User{}, false
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return User{}, false
+	}
+	return user, true
+}
+
+// listUsers returns all accounts sorted by name
+func listUsers() []User {
+	usersMu.Lock()
+	defer usersMu.Unlock()
+
+	list := make([]User, 0, len(users))
+	for _, user := range users {
+		list = append(list, user)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Username < list[j].Username
+	})
+	return list
+}
@@ -3,22 +3,62 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 type Config struct {
-	DiscordToken            string `json:"discordToken"`
-	ControlChannelID        string `json:"controlChannelID"`
-	StatusChannelID         string `json:"statusChannelID"`
-	ConnectionListChannelID string `json:"connectionListChannelID"`
-	LogChannelID            string `json:"logChannelID"`
-	SaveChannelID           string `json:"saveChannelID"`
-	ControlPanelChannelID   string `json:"controlPanelChannelID"`
-	BlackListFilePath       string `json:"blackListFilePath"`
-	IsDiscordEnabled        bool   `json:"isDiscordEnabled"`
-	ErrorChannelID          string `json:"errorChannelID"`
+	DiscordToken            string            `json:"discordToken"`
+	ControlChannelID        string            `json:"controlChannelID"`
+	StatusChannelID         string            `json:"statusChannelID"`
+	ConnectionListChannelID string            `json:"connectionListChannelID"`
+	LogChannelID            string            `json:"logChannelID"`
+	SaveChannelID           string            `json:"saveChannelID"`
+	ControlPanelChannelID   string            `json:"controlPanelChannelID"`
+	BlackListFilePath       string            `json:"blackListFilePath"`
+	IsDiscordEnabled        bool              `json:"isDiscordEnabled"`
+	ErrorChannelID          string            `json:"errorChannelID"`
+	AutoRestartEnabled      bool              `json:"autoRestartEnabled"`
+	RestartBackoffSeconds   int               `json:"restartBackoffSeconds"`
+	RestartBackoffMaxSecs   int               `json:"restartBackoffMaxSeconds"`
+	MaxRestartsPerHour      int               `json:"maxRestartsPerHour"`
+	StopSaveCommand         *string           `json:"stopSaveCommand"` // missing keeps the default, "" skips the step
+	StopQuitCommand         *string           `json:"stopQuitCommand"`
+	StopSaveTimeoutSecs     int               `json:"stopSaveTimeoutSeconds"`
+	StopQuitTimeoutSecs     int               `json:"stopQuitTimeoutSeconds"`
+	StopTermTimeoutSecs     int               `json:"stopTermTimeoutSeconds"`
+	ConsoleAllowedCommands  []string          `json:"consoleAllowedCommands"`
+	LogMaxSizeMB            int               `json:"logMaxSizeMB"`
+	LogMaxAgeHours          int               `json:"logMaxAgeHours"`
+	LogRetentionDays        int               `json:"logRetentionDays"`
+	Webhooks                []Webhook         `json:"webhooks"`
+	ExceptionPolicies       []ExceptionPolicy `json:"exceptionPolicies"`
+	RestartWarnings         []string          `json:"restartWarnings"`
+	RestartWarningMessage   string            `json:"restartWarningMessage"`
+	IdleStopMinutes         int               `json:"idleStopMinutes"`
+	DetachServer            bool              `json:"detachServer"`
+	MemoryLimitMB           int               `json:"memoryLimitMB"`
+	MemoryLimitMinutes      int               `json:"memoryLimitMinutes"`
+}
+
+// Webhook receives server events as JSON POST requests
+type Webhook struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"` // event types to send, all events if empty
+	Secret string   `json:"secret"` // signs the body as X-SSUI-Signature: sha256=<hex HMAC>
+}
+
+// ExceptionPolicy restarts or stops the server when matching exceptions happen too often
+type ExceptionPolicy struct {
+	Name            string   `json:"name"`
+	Signatures      []string `json:"signatures"`      // exception signatures as listed by /api/exceptions
+	Pattern         string   `json:"pattern"`         // regular expression matched against the first line of the exception
+	Threshold       int      `json:"threshold"`       // occurrences within the window that trigger the action
+	WindowSeconds   int      `json:"windowSeconds"`   // length of the sliding window
+	Action          string   `json:"action"`          // "restart" or "stop"
+	CooldownSeconds int      `json:"cooldownSeconds"` // no further action of this policy for this long
 }
 
 var (
@@ -34,7 +74,8 @@ var (
 	LogMessageBuffer          string
 	MaxBufferSize             = 1000
 	BufferFlushTicker         *time.Ticker
-	ConnectedPlayers          = make(map[string]string) // SteamID -> Username
+	ConnectedPlayers          = make(map[string]string) // SteamID -> Username, tracked from the server events
+	ConnectedPlayersMu        sync.Mutex                // guards ConnectedPlayers
 	ConnectedPlayersMessageID string
 	ControlMessageID          string
 	ExceptionMessageID        string
@@ -42,6 +83,27 @@ var (
 	ControlPanelChannelID     string
 	IsDiscordEnabled          bool
 	IsFirstTimeSetup          bool
+	AutoRestartEnabled        bool
+	RestartBackoffSeconds     = 10     // delay before the first automatic restart after a crash
+	RestartBackoffMaxSecs     = 300    // upper bound for the exponential restart backoff
+	MaxRestartsPerHour        = 5      // circuit breaker: stop restarting after this many restarts within an hour
+	StopSaveCommand           = "save" // console command sent first on stop, the stop waits for "World Saved", "" stops without saving
+	StopQuitCommand           = "quit" // console command sent after saving to let the server exit on its own, "" goes straight to SIGTERM
+	StopSaveTimeoutSecs       = 60
+	StopQuitTimeoutSecs       = 30
+	StopTermTimeoutSecs       = 30                                                          // after this the process is killed
+	ConsoleAllowedCommands    = []string{"say", "save", "clients", "kick", "ban", "status"} // "*" allows every console command
+	LogMaxSizeMB              = 10                                                          // server log files are rotated when they reach this size...
+	LogMaxAgeHours            = 24                                                          // ...or this age, rotated files are gzipped
+	LogRetentionDays          = 14                                                          // server log files older than this are deleted
+	Webhooks                  []Webhook
+	ExceptionPolicies         []ExceptionPolicy
+	RestartWarnings           = []string{"15m", "5m", "1m", "10s"}                                       // countdown restarts warn the players this long before the restart
+	RestartWarningMessage     = "Server restarts in {time}. Find a safe spot, the world is saved first." // {time} is replaced by the remaining time
+	IdleStopMinutes           int                                                                        // stop the server after this many minutes without players, 0 keeps it running
+	DetachServer              bool                                                                       // Linux: run the server in its own session with console and output in files, so it survives controller restarts
+	MemoryLimitMB             int                                                                        // Linux: restart with warnings when the server uses more memory than this, 0 disables the memory guard
+	MemoryLimitMinutes        = 10                                                                       // ...for this long without a break
 	Version                   = "2.4.3"
 	Branch                    = "Release"
 )
@@ -80,5 +142,57 @@ func LoadConfig(filename string) (*Config, error) {
 	ControlPanelChannelID = config.ControlPanelChannelID
 	IsDiscordEnabled = config.IsDiscordEnabled
 	ErrorChannelID = config.ErrorChannelID
+	AutoRestartEnabled = config.AutoRestartEnabled
+	// Keep the defaults for supervisor settings that are missing from older config files
+	if config.RestartBackoffSeconds > 0 {
+		RestartBackoffSeconds = config.RestartBackoffSeconds
+	}
+	if config.RestartBackoffMaxSecs > 0 {
+		RestartBackoffMaxSecs = config.RestartBackoffMaxSecs
+	}
+	if config.MaxRestartsPerHour > 0 {
+		MaxRestartsPerHour = config.MaxRestartsPerHour
+	}
+	if config.StopSaveCommand != nil {
+		StopSaveCommand = *config.StopSaveCommand
+	}
+	if config.StopQuitCommand != nil {
+		StopQuitCommand = *config.StopQuitCommand
+	}
+	if config.StopSaveTimeoutSecs > 0 {
+		StopSaveTimeoutSecs = config.StopSaveTimeoutSecs
+	}
+	if config.StopQuitTimeoutSecs > 0 {
+		StopQuitTimeoutSecs = config.StopQuitTimeoutSecs
+	}
+	if config.StopTermTimeoutSecs > 0 {
+		StopTermTimeoutSecs = config.StopTermTimeoutSecs
+	}
+	if config.ConsoleAllowedCommands != nil {
+		ConsoleAllowedCommands = config.ConsoleAllowedCommands
+	}
+	if config.LogMaxSizeMB > 0 {
+		LogMaxSizeMB = config.LogMaxSizeMB
+	}
+	if config.LogMaxAgeHours > 0 {
+		LogMaxAgeHours = config.LogMaxAgeHours
+	}
+	if config.LogRetentionDays > 0 {
+		LogRetentionDays = config.LogRetentionDays
+	}
+	Webhooks = config.Webhooks
+	ExceptionPolicies = config.ExceptionPolicies
+	if len(config.RestartWarnings) > 0 {
+		RestartWarnings = config.RestartWarnings
+	}
+	if config.RestartWarningMessage != "" {
+		RestartWarningMessage = config.RestartWarningMessage
+	}
+	IdleStopMinutes = config.IdleStopMinutes
+	DetachServer = config.DetachServer
+	MemoryLimitMB = config.MemoryLimitMB
+	if config.MemoryLimitMinutes > 0 {
+		MemoryLimitMinutes = config.MemoryLimitMinutes
+	}
 	return &config, nil
 }
@@ -7,6 +7,9 @@ import (
 )
 
 func formatConnectedPlayers() string {
+	config.ConnectedPlayersMu.Lock()
+	defer config.ConnectedPlayersMu.Unlock()
+
 	if len(config.ConnectedPlayers) == 0 {
 		return "No players are currently connected."
 	}
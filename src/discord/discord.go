@@ -3,7 +3,7 @@ package discord
 import (
 	"StationeersServerUI/src/config"
 	"fmt"
-	"regexp"
+	"net/http"
 	"strings"
 	"time"
 
@@ -25,6 +25,12 @@ func StartDiscordBot() {
 	}
 	fmt.Println("Bot is now running and connected")
 
+	transport := config.DiscordSession.Client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	config.DiscordSession.Client.Transport = countingTransport{transport}
+
 	config.DiscordSession.AddHandler(messageCreate)
 	config.DiscordSession.AddHandler(reactionAddHandler)
 
@@ -35,9 +41,10 @@ func StartDiscordBot() {
 	}
 
 	fmt.Println("Bot is now running.")
+	go handleServerEvents()
 	// Start the buffer flush ticker to send the remaining buffer every 5 seconds
 	config.BufferFlushTicker = time.NewTicker(5 * time.Second)
-	sendMessageToStatusChannel("🤖 Bot Version " + config.Version + " Branch " + config.Branch + "connected to Discord.")
+	SendMessageToStatusChannel("🤖 Bot Version " + config.Version + " Branch " + config.Branch + "connected to Discord.")
 	go func() {
 		for range config.BufferFlushTicker.C {
 			flushLogBufferToDiscord()
@@ -47,102 +54,15 @@ func StartDiscordBot() {
 	select {} // Keep the program running
 }
 
-func checkForKeywords(logMessage string) {
-	// List of keywords to detect and their corresponding messages
-	keywordActions := map[string]string{
-		"Ready":                               "🔔Server is ready to connect!",
-		"Unloading 1 Unused Serialized files": "🕑Server made a critical step towards availability, will be ready to connect soon!",
-		"EXCEPTION":                           "Server Error detected.",
-		// "No clients connected.": "No clients connected. Server is going into idle mode!",
+// StopDiscordBot sends what is left in the log buffer and closes the Discord connection
+func StopDiscordBot() {
+	if config.BufferFlushTicker != nil {
+		config.BufferFlushTicker.Stop()
 	}
-
-	// Iterate through the keywordActions map
-	for keyword, actionMessage := range keywordActions {
-		if strings.Contains(logMessage, keyword) {
-			sendMessageToStatusChannel(actionMessage)
-		}
-	}
-
-	// Detect more complex patterns using regex
-	complexPatterns := []struct {
-		pattern *regexp.Regexp
-		handler func(matches []string)
-	}{
-		{
-			// Example: "Client Jacksonthemaster (76561198334231312) is ready!"
-			pattern: regexp.MustCompile(`Client\s+(.+)\s+\((\d+)\)\s+is\s+ready!`),
-			handler: func(matches []string) {
-				username := matches[1]
-				steamID := matches[2]
-				message := fmt.Sprintf("🔗Client %s (Steam ID: %s) is ready!", username, steamID)
-				sendMessageToStatusChannel(message)
-
-				config.ConnectedPlayers[steamID] = username
-				updateConnectedPlayersMessage(config.ConnectionListChannelID)
-			},
-		},
-		{
-			// Example: "Client Jacksonthemaster (76561198334231312). Receiving"
-			pattern: regexp.MustCompile(`Client:?\s+(.+?)\s+\((\d+)\)\.\s+Receiving`),
-			handler: func(matches []string) {
-				username := matches[1]
-				steamID := matches[2]
-				message := fmt.Sprintf("🔗Client %s (Steam ID: %s) is trying to connect...", username, steamID)
-				sendMessageToStatusChannel(message)
-			},
-		},
-		{
-			// Example: "Client disconnected: 135108291984612402 | Jacksonthemaster"
-			pattern: regexp.MustCompile(`Client\s+disconnected:\s+\d+\s+\|\s+(.+)\s+connectTime:\s+\d+,\d+s,\s+ClientId:\s+(\d+)`),
-			handler: func(matches []string) {
-				username := matches[1]
-				steamID := matches[2]
-				message := fmt.Sprintf("\u200dClient %s disconnected.", username)
-				sendMessageToStatusChannel(message)
-
-				delete(config.ConnectedPlayers, steamID)
-				updateConnectedPlayersMessage(config.ConnectionListChannelID)
-				updateBotStatus(config.DiscordSession) // Update bot status
-			},
-		},
-		{
-			// Enhanced "World Saved" pattern: "World Saved: C:/SteamCMD/Stationeers/saves/EuropaProd, BackupIndex: 1057"
-			pattern: regexp.MustCompile(`World Saved:\s.*,\sBackupIndex:\s(\d+)`),
-			handler: func(matches []string) {
-				backupIndex := matches[1]
-				currentTime := time.Now().UTC().Format(time.RFC3339)
-				message := fmt.Sprintf("💾World Saved: BackupIndex: %s UTCTime: %s", backupIndex, currentTime)
-				SendMessageToSavesChannel(message)
-				updateBotStatus(config.DiscordSession) // Update bot status
-			},
-		},
-		{
-			// Detect messy exceptions (based on common patterns in the stack trace)
-			pattern: regexp.MustCompile(`(?m)^\s*>\s*\d{2}:\d{2}:\d{2}:.*Exception.*|>\s+\d{2}:\d{2}:\d{2}:.*StackTrace`),
-			handler: func(matches []string) {
-				// Gather the full exception by collecting all subsequent lines that start with "> HH:MM:SS"
-				exception := logMessage
-
-				// Send the complete exception to the dedicated error channel and capture the sent messages
-				sentMessages := sendMessageToErrorChannel(fmt.Sprintf("🚨 Exception detected:\n```\n%s\n```", exception))
-
-				// Add reactions to each message that was sent
-				for _, msg := range sentMessages {
-					config.DiscordSession.MessageReactionAdd(config.ErrorChannelID, msg.ID, "♻️") // restart server
-				}
-
-				// Optionally store the last message ID for further handling (e.g., reaction tracking)
-				if len(sentMessages) > 0 {
-					config.ExceptionMessageID = sentMessages[len(sentMessages)-1].ID
-				}
-			},
-		},
-		// Add more complex patterns and handlers here
-	}
-
-	for _, cp := range complexPatterns {
-		if matches := cp.pattern.FindStringSubmatch(logMessage); matches != nil {
-			cp.handler(matches)
+	flushLogBufferToDiscord()
+	if config.DiscordSession != nil {
+		if err := config.DiscordSession.Close(); err != nil {
+			fmt.Println("Error closing Discord connection:", err)
 		}
 	}
 }
@@ -156,17 +76,29 @@ func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 
 	switch {
 	case strings.HasPrefix(content, "!start"):
-		SendCommandToAPI("/start")
-		s.ChannelMessageSend(m.ChannelID, "🕛Server is starting...")
-		sendMessageToStatusChannel("🕛Start command received from Server Controller, Server is Starting...")
+		message, ok := startServer()
+		s.ChannelMessageSend(m.ChannelID, message)
+		if ok {
+			SendMessageToStatusChannel("🕛Start command received from Server Controller, Server is Starting...")
+		}
 
 	case strings.HasPrefix(content, "!stop"):
-		SendCommandToAPI("/stop")
 		s.ChannelMessageSend(m.ChannelID, "🕛Server is stopping...")
-		sendMessageToStatusChannel("🕛Stop command received from Server Controller, flatlining Server in 5 Seconds...")
+		SendMessageToStatusChannel("🕛Stop command received from Server Controller, flatlining Server in 5 Seconds...")
+		message, _ := stopServer()
+		s.ChannelMessageSend(m.ChannelID, message)
+
+	case strings.HasPrefix(content, "!wake"):
+		handleWakeCommand(s, m)
+
+	case strings.HasPrefix(content, "!restart"):
+		handleRestartCommand(s, m)
+
+	case strings.HasPrefix(content, "!cancelrestart"):
+		handleCancelRestartCommand(s, m)
 
 	case strings.HasPrefix(content, "!restore"):
-		sendMessageToStatusChannel("⚠️Restore command received, flatlining and restoring Server in 5 Seconds. Server will come back online in about 60 Seconds.")
+		SendMessageToStatusChannel("⚠️Restore command received, flatlining and restoring Server in 5 Seconds. Server will come back online in about 60 Seconds.")
 		handleRestoreCommand(s, m, content)
 
 	case strings.HasPrefix(content, "!list"):
@@ -186,6 +118,9 @@ func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 
 	case strings.HasPrefix(content, "!validate"):
 		handleValidateCommand(s, m.ChannelID)
+
+	case strings.HasPrefix(content, "!console"):
+		handleConsoleCommand(s, m, content)
 	default:
 		// Optionally handle unrecognized commands or ignore them
 	}
@@ -0,0 +1,46 @@
+package discord
+
+import (
+	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/events"
+	"fmt"
+	"time"
+)
+
+// handleServerEvents reports server events in the Discord channels for as long as the bot runs
+func handleServerEvents() {
+	sub := events.Subscribe("Discord", 256)
+	for e := range sub.Events() {
+		switch e.Type {
+		case events.ServerLoading:
+			SendMessageToStatusChannel("🕑Server made a critical step towards availability, will be ready to connect soon!")
+
+		case events.ServerReady:
+			SendMessageToStatusChannel("🔔Server is ready to connect!")
+
+		case events.PlayerConnecting:
+			SendMessageToStatusChannel(fmt.Sprintf("🔗Client %s (Steam ID: %s) is trying to connect...", e.Player, e.SteamID))
+
+		case events.PlayerReady:
+			SendMessageToStatusChannel(fmt.Sprintf("🔗Client %s (Steam ID: %s) is ready!", e.Player, e.SteamID))
+			updateConnectedPlayersMessage(config.ConnectionListChannelID)
+			updateBotStatus(config.DiscordSession)
+
+		case events.PlayerDisconnected:
+			SendMessageToStatusChannel(fmt.Sprintf("\u200dClient %s disconnected.", e.Player))
+			updateConnectedPlayersMessage(config.ConnectionListChannelID)
+			updateBotStatus(config.DiscordSession)
+
+		case events.WorldSaved:
+			SendMessageToSavesChannel(fmt.Sprintf("💾World Saved: BackupIndex: %d UTCTime: %s", e.BackupIndex, e.Time.UTC().Format(time.RFC3339)))
+			updateBotStatus(config.DiscordSession)
+
+		case events.Exception:
+			reportException(e)
+
+		case events.ProcessExited:
+			updateConnectedPlayersMessage(config.ConnectionListChannelID)
+			updateBotStatus(config.DiscordSession)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package discord
+
+import (
+	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/events"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	exceptionEditInterval = 10 * time.Second // limits how often the message of a looping exception is edited
+	maxExceptionPosts     = 500              // the least recently seen posts are forgotten beyond this, like the exception groups
+)
+
+// exceptionPost is the error channel message of one exception signature
+type exceptionPost struct {
+	messageID string
+	firstSeen time.Time
+	latest    events.Event
+	lastEdit  time.Time
+	editTimer *time.Timer // pending edit, set while edits are throttled
+}
+
+var (
+	exceptionPostsMu sync.Mutex
+	exceptionPosts   = make(map[string]*exceptionPost) // by signature
+)
+
+// reportException posts the first occurrence of an exception and edits the occurrence counter of that message for repeats.
+// Discord is called without holding exceptionPostsMu, so a slow request does not hold up the other exceptions.
+func reportException(e events.Event) {
+	exceptionPostsMu.Lock()
+	post, ok := exceptionPosts[e.Signature]
+	if ok && e.Count > 1 {
+		post.latest = e
+		editNow := post.messageID != "" && scheduleEditLocked(post)
+		exceptionPostsMu.Unlock()
+		if editNow {
+			editException(post)
+		}
+		return
+	}
+
+	// New exception or the first one after a quiet window, post a new message
+	if ok && post.editTimer != nil {
+		post.editTimer.Stop()
+	}
+	post = &exceptionPost{firstSeen: e.Time, latest: e}
+	exceptionPosts[e.Signature] = post
+	forgetOldPostsLocked()
+	content := formatException(post)
+	exceptionPostsMu.Unlock()
+
+	sentMessages := sendMessageToErrorChannel(content)
+	if len(sentMessages) == 0 {
+		return
+	}
+	messageID := sentMessages[0].ID
+	config.DiscordSession.MessageReactionAdd(config.ErrorChannelID, messageID, "♻️") // restart server
+
+	exceptionPostsMu.Lock()
+	defer exceptionPostsMu.Unlock()
+	post.messageID = messageID
+	post.lastEdit = time.Now()
+	config.ExceptionMessageID = messageID
+	if post.latest.Count != e.Count {
+		// Repeats arrived while the message was sent, they show up with the next edit
+		scheduleEditLocked(post)
+	}
+}
+
+// scheduleEditLocked reports whether the post may be edited right away, otherwise it schedules
+// one edit for when the interval has passed. The caller must hold exceptionPostsMu.
+func scheduleEditLocked(post *exceptionPost) bool {
+	if post.editTimer != nil {
+		return false // the pending edit will show the latest count
+	}
+	wait := exceptionEditInterval - time.Since(post.lastEdit)
+	if wait <= 0 {
+		return true
+	}
+	post.editTimer = time.AfterFunc(wait, func() { editException(post) })
+	return false
+}
+
+// editException updates the counter of a posted exception
+func editException(post *exceptionPost) {
+	exceptionPostsMu.Lock()
+	post.editTimer = nil
+	if post.messageID == "" || config.DiscordSession == nil {
+		exceptionPostsMu.Unlock()
+		return
+	}
+	post.lastEdit = time.Now()
+	messageID, content := post.messageID, formatException(post)
+	exceptionPostsMu.Unlock()
+
+	if _, err := config.DiscordSession.ChannelMessageEdit(config.ErrorChannelID, messageID, content); err != nil {
+		fmt.Println("Error editing exception message:", err)
+	}
+}
+
+// forgetOldPostsLocked drops the least recently seen posts beyond the limit, the caller must hold exceptionPostsMu
+func forgetOldPostsLocked() {
+	if len(exceptionPosts) <= maxExceptionPosts {
+		return
+	}
+	var oldest *exceptionPost
+	for _, post := range exceptionPosts {
+		if oldest == nil || post.latest.Time.Before(oldest.latest.Time) {
+			oldest = post
+		}
+	}
+	if oldest.editTimer != nil {
+		oldest.editTimer.Stop()
+	}
+	delete(exceptionPosts, oldest.latest.Signature)
+}
+
+// formatException renders an exception with its occurrence counter, the trace is cut to fit into one message
+func formatException(post *exceptionPost) string {
+	const maxTraceLength = 1500
+
+	trace := post.latest.Message
+	if len(trace) > maxTraceLength {
+		// Cut at the start of a character, never inside one
+		cut := maxTraceLength
+		for cut > 0 && !utf8.RuneStart(trace[cut]) {
+			cut--
+		}
+		trace = trace[:cut] + "\n..."
+	}
+	header := "🚨 Exception detected"
+	if post.latest.Count > 1 {
+		header = fmt.Sprintf("🚨 Exception detected **%d times** (first %s UTC, last %s UTC)",
+			post.latest.Count, post.firstSeen.UTC().Format("15:04:05"), post.latest.Time.UTC().Format("15:04:05"))
+	}
+	return fmt.Sprintf("%s `%s`:\n```\n%s\n```", header, post.latest.Signature, trace)
+}
+
+// isExceptionMessage reports whether a message is one of the posted exceptions
+func isExceptionMessage(messageID string) bool {
+	exceptionPostsMu.Lock()
+	defer exceptionPostsMu.Unlock()
+
+	for _, post := range exceptionPosts {
+		if post.messageID == messageID {
+			return true
+		}
+	}
+	return messageID == config.ExceptionMessageID
+}
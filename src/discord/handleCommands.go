@@ -1,12 +1,10 @@
 package discord
 
 import (
-	"StationeersServerUI/src/config"
+	"StationeersServerUI/src/lifecycle"
+	"StationeersServerUI/src/service"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
@@ -19,12 +17,16 @@ func handleHelpCommand(s *discordgo.Session, channelID string) {
 **Available Commands:**
 - ` + "`!start`" + `: Starts the server.
 - ` + "`!stop`" + `: Stops the server.
+- ` + "`!wake`" + `: Starts the server again after it was stopped for having no players.
+- ` + "`!restart`" + `: Restarts the server after warning the players in game.
+- ` + "`!cancelrestart`" + `: Cancels a restart until the final warning is sent.
 - ` + "`!restore:<index>`" + `: Restores a backup at the specified index. Usage: ` + "`!restore:1`" + `.
 - ` + "`!list:<number/all>`" + `: Lists the most recent backups. Use ` + "`!list:all`" + ` to list all backups or ` + "`!list:<number>`" + ` to specify how many to list.
 - ` + "`!ban:<SteamID>`" + `: Bans a player by their SteamID. Usage: ` + "`!ban:76561198334231312`" + `.
 - ` + "`!unban:<SteamID>`" + `: Unbans a player by their SteamID. Usage: ` + "`!unban:76561198334231312`" + `.
 - ` + "`!update`" + `: Updates the server files if there is a game update available. (Currently Stable Branch only)
 - ` + "`!validate`" + `: Validates the server files if there is a game update available. (Currently Stable Branch only)
+- ` + "`!console <command>`" + `: Sends a command to the server console, e.g. ` + "`!console say Restart in 5 minutes`" + `. Only allowlisted commands are accepted.
 - ` + "`!help`" + `: Displays this help message.
 
 Please stop the server before using update commands.
@@ -39,6 +41,44 @@ Please stop the server before using update commands.
 	}
 }
 
+func handleRestartCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
+	restartAt, err := Server.RestartWithCountdown("requested on Discord", m.Author.Username)
+	switch {
+	case errors.Is(err, service.ErrServerNotRunning):
+		s.ChannelMessageSend(m.ChannelID, "❌Server is not running, use !start instead.")
+	case errors.Is(err, service.ErrCountdownActive):
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("⚠️A restart is already planned for %s.", restartAt.Format("15:04:05")))
+	case err != nil:
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("❌Could not start the restart countdown: %v", err))
+	default:
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("🕛Players are being warned, the server restarts at %s. Use !cancelrestart to cancel.", restartAt.Format("15:04:05")))
+	}
+}
+
+func handleWakeCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
+	err := Server.Wake(m.Author.Username)
+	switch {
+	case errors.Is(err, service.ErrNotSleeping):
+		s.ChannelMessageSend(m.ChannelID, "ℹ️The server is not asleep. "+err.Error()+".")
+	case err != nil:
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("❌Could not wake the server: %v", err))
+	default:
+		s.ChannelMessageSend(m.ChannelID, "☀️Server is waking up...")
+	}
+}
+
+func handleCancelRestartCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
+	err := Server.CancelRestart(m.Author.Username)
+	switch {
+	case errors.Is(err, service.ErrNoCountdown):
+		s.ChannelMessageSend(m.ChannelID, "ℹ️No restart is planned.")
+	case err != nil:
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("❌%v", err))
+	default:
+		s.ChannelMessageSend(m.ChannelID, "✅Restart cancelled.")
+	}
+}
+
 func handleListCommand(s *discordgo.Session, channelID string, content string) {
 	fmt.Println("!list command received, fetching backup list...")
 
@@ -59,33 +99,20 @@ func handleListCommand(s *discordgo.Session, channelID string, content string) {
 	}
 
 	// Step 1: Fetch the backup list from the server
-	resp, err := http.Get("http://localhost:8080/backups")
+	backups, err := Server.ListBackups()
 	if err != nil {
 		fmt.Println("Failed to fetch backup list:", err)
 		s.ChannelMessageSend(channelID, "❌Failed to fetch backup list.")
 		return
 	}
-	defer resp.Body.Close()
 
-	// Step 2: Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Println("Failed to read backup list response:", err)
-		s.ChannelMessageSend(channelID, "❌Failed to read backup list.")
-		return
+	// Step 2: Format one line per backup
+	var lines []string
+	for _, backup := range backups {
+		lines = append(lines, formatBackup(backup))
 	}
 
-	// Step 3: Output the raw backup list data for debugging
-	//fmt.Println("Raw backup list data:", string(body))
-
-	// Step 4: Parse the backup list data into a formatted string
-	backupList := parseBackupList(string(body))
-	//fmt.Println("Formatted backup list:\n", backupList)
-
-	// Step 5: Split the backup list into individual lines
-	lines := strings.Split(backupList, "\n")
-
-	// Step 6: Send each line as a separate message, respecting the "top" limit
+	// Step 3: Send each line as a separate message, respecting the "top" limit
 	count := 0
 	for _, line := range lines {
 		if strings.TrimSpace(line) == "" {
@@ -112,88 +139,76 @@ func handleRestoreCommand(s *discordgo.Session, m *discordgo.MessageCreate, cont
 	parts := strings.Split(content, ":")
 	if len(parts) != 2 {
 		s.ChannelMessageSend(m.ChannelID, "❌Invalid restore command. Use `!restore:<index>`.")
-		sendMessageToStatusChannel("⚠️Restore command received, but not able to restore Server.")
+		SendMessageToStatusChannel("⚠️Restore command received, but not able to restore Server.")
 		return
 	}
-	SendCommandToAPI("/stop")
 	indexStr := parts[1]
 	index, err := strconv.Atoi(indexStr)
 	if err != nil {
 		s.ChannelMessageSend(m.ChannelID, "❌Invalid index provided for restore.")
-		sendMessageToStatusChannel("⚠️Restore command received, but not able to restore Server.")
+		SendMessageToStatusChannel("⚠️Restore command received, but not able to restore Server.")
 		return
 	}
 
-	url := fmt.Sprintf("http://localhost:8080/restore?index=%d", index)
-	resp, err := http.Get(url)
-	if err != nil || resp.StatusCode != http.StatusOK {
-		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("❌Failed to restore backup at index %d.", index))
-		sendMessageToStatusChannel("⚠️Restore command received, but not able to restore Server.")
+	if err := restoreBackup(index); err != nil {
+		fmt.Printf("Failed to restore backup %d: %v\n", index, err)
+		s.ChannelMessageSend(m.ChannelID, describeRestoreError(index, err))
+		SendMessageToStatusChannel("⚠️Restore command received, but not able to restore Server.")
 		return
 	}
 
 	s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("✅Backup %d restored successfully, Starting Server...", index))
-	//sleep 5 sec to give the server time to start
-	time.Sleep(5 * time.Second)
-	SendCommandToAPI("/start")
+	if message, ok := startServer(); !ok {
+		s.ChannelMessageSend(m.ChannelID, message)
+	}
 }
 
-func handleUpdateCommand(s *discordgo.Session, channelID string) {
-	// Notify that the update process is starting
-	s.ChannelMessageSend(channelID, "🕛Starting the server update process...")
-
-	// PowerShell command to run SteamCMD
-	powerShellScript := `
-		cd C:\SteamCMD
-		.\steamcmd +force_install_dir C:/SteamCMD/Stationeers/ +login anonymous +app_update 600760 -beta public +quit
-	`
+func handleConsoleCommand(s *discordgo.Session, m *discordgo.MessageCreate, content string) {
+	command := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(content, "!console"), ":"))
+	if command == "" {
+		s.ChannelMessageSend(m.ChannelID, "❌Invalid console command. Use `!console <command>`.")
+		return
+	}
 
-	// Execute the PowerShell command
-	cmd := exec.Command("powershell", "-Command", powerShellScript)
-	err := cmd.Start()
+	err := Server.SendConsoleCommand("discord", m.Author.Username, command)
 	if err != nil {
-		fmt.Printf("Error starting update command: %v\n", err)
-		s.ChannelMessageSend(channelID, "❌Failed to start the update process.")
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("❌Console command failed: %v", err))
 		return
 	}
 
-	// Wait for the process to complete
-	err = cmd.Wait()
-	if err != nil {
-		fmt.Printf("Error during update process: %v\n", err)
+	s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("✅Sent to server console: `%s`", command))
+}
+
+func handleUpdateCommand(s *discordgo.Session, channelID string) {
+	// Notify that the update process is starting
+	s.ChannelMessageSend(channelID, "🕛Starting the server update process...")
+
+	err := Server.Update(false)
+	var transitionErr *lifecycle.TransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		s.ChannelMessageSend(channelID, fmt.Sprintf("❌Cannot update: %v. Please stop the server first.", err))
+	case err != nil:
+		fmt.Println(err)
 		s.ChannelMessageSend(channelID, "❌The update process encountered an error.")
-	} else {
-		// Notify that the update process has finished
+	default:
 		s.ChannelMessageSend(channelID, "✅Game Update process completed successfully. Server is up to date.")
 	}
 }
 
 func handleValidateCommand(s *discordgo.Session, channelID string) {
-	// Notify that the update process is starting
+	// Notify that the validate process is starting
 	s.ChannelMessageSend(channelID, "🕛Starting the server validate process...")
 
-	// PowerShell command to run SteamCMD
-	powerShellScript := `
-		cd C:\SteamCMD
-		.\steamcmd +force_install_dir C:/SteamCMD/Stationeers/ +login anonymous +app_update 600760 -beta public -validate +quit
-	`
-
-	// Execute the PowerShell command
-	cmd := exec.Command("powershell", "-Command", powerShellScript)
-	err := cmd.Start()
-	if err != nil {
-		fmt.Printf("Error starting update command: %v\n", err)
-		s.ChannelMessageSend(channelID, "❌Failed to start the validate process.")
-		return
-	}
-
-	// Wait for the process to complete
-	err = cmd.Wait()
-	if err != nil {
-		fmt.Printf("Error during update process: %v\n", err)
+	err := Server.Update(true)
+	var transitionErr *lifecycle.TransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		s.ChannelMessageSend(channelID, fmt.Sprintf("❌Cannot validate: %v. Please stop the server first.", err))
+	case err != nil:
+		fmt.Println(err)
 		s.ChannelMessageSend(channelID, "❌The validate process encountered an error.")
-	} else {
-		// Notify that the update process has finished
+	default:
 		s.ChannelMessageSend(channelID, "✅Game validate process completed successfully. Server is valid, but custom changes are overwritten.")
 	}
 }
@@ -205,32 +220,7 @@ func handleBanCommand(s *discordgo.Session, channelID string, content string) {
 		s.ChannelMessageSend(channelID, "❌Invalid ban command. Use `!ban:<SteamID>`.")
 		return
 	}
-	steamID := strings.TrimSpace(parts[1])
-
-	// Read the current blacklist
-	blacklist, err := readBlacklist(config.BlackListFilePath)
-	if err != nil {
-		s.ChannelMessageSend(channelID, "❌Error reading blacklist file.")
-		return
-	}
-
-	// Check if the SteamID is already in the blacklist
-	if strings.Contains(blacklist, steamID) {
-		s.ChannelMessageSend(channelID, fmt.Sprintf("❌SteamID %s is already banned.", steamID))
-		return
-	}
-
-	// Add the SteamID to the blacklist
-	blacklist = appendToBlacklist(blacklist, steamID)
-
-	// Write the updated blacklist back to the file
-	err = os.WriteFile(config.BlackListFilePath, []byte(blacklist), 0644)
-	if err != nil {
-		s.ChannelMessageSend(channelID, "❌Error writing to blacklist file.")
-		return
-	}
-
-	s.ChannelMessageSend(channelID, fmt.Sprintf("✅SteamID %s has been banned.", steamID))
+	setBanned(s, channelID, strings.TrimSpace(parts[1]), true)
 }
 
 func handleUnbanCommand(s *discordgo.Session, channelID string, content string) {
@@ -240,30 +230,26 @@ func handleUnbanCommand(s *discordgo.Session, channelID string, content string)
 		s.ChannelMessageSend(channelID, "❌Invalid unban command. Use `!unban:<SteamID>`.")
 		return
 	}
-	steamID := strings.TrimSpace(parts[1])
+	setBanned(s, channelID, strings.TrimSpace(parts[1]), false)
+}
 
-	// Read the current blacklist
-	blacklist, err := readBlacklist(config.BlackListFilePath)
+// setBanned updates the blacklist and reports the outcome
+func setBanned(s *discordgo.Session, channelID, steamID string, banned bool) {
+	changed, err := Server.SetBanned(steamID, banned)
 	if err != nil {
-		s.ChannelMessageSend(channelID, "❌Error reading blacklist file.")
+		fmt.Println("Error updating blacklist:", err)
+		s.ChannelMessageSend(channelID, fmt.Sprintf("❌Error updating blacklist: %v", err))
 		return
 	}
 
-	// Check if the SteamID is in the blacklist
-	if !strings.Contains(blacklist, steamID) {
-		s.ChannelMessageSend(channelID, fmt.Sprintf("✅SteamID %s is not banned.", steamID))
-		return
+	switch {
+	case banned && changed:
+		s.ChannelMessageSend(channelID, fmt.Sprintf("✅SteamID %s has been banned.", steamID))
+	case banned:
+		s.ChannelMessageSend(channelID, fmt.Sprintf("⚠️SteamID %s is already banned.", steamID))
+	case changed:
+		s.ChannelMessageSend(channelID, fmt.Sprintf("✅SteamID %s has been unbanned.", steamID))
+	default:
+		s.ChannelMessageSend(channelID, fmt.Sprintf("⚠️SteamID %s is not banned.", steamID))
 	}
-
-	// Remove the SteamID from the blacklist
-	updatedBlacklist := removeFromBlacklist(blacklist, steamID)
-
-	// Write the updated blacklist back to the file
-	err = os.WriteFile(config.BlackListFilePath, []byte(updatedBlacklist), 0644)
-	if err != nil {
-		s.ChannelMessageSend(channelID, "❌Error writing to blacklist file.")
-		return
-	}
-
-	s.ChannelMessageSend(channelID, fmt.Sprintf("✅SteamID %s has been unbanned.", steamID))
 }
@@ -3,27 +3,44 @@ package discord
 import (
 	"StationeersServerUI/src/config"
 	"fmt"
+	"sync"
+)
+
+var (
+	logBufferMu sync.Mutex // guards config.LogMessageBuffer
+	logSendMu   sync.Mutex // keeps flushes from the output and the ticker in order
 )
 
 func AddToLogBuffer(logMessage string) {
+	logBufferMu.Lock()
 	config.LogMessageBuffer += logMessage + "\n" // Add the log message to the buffer with a newline
-	checkForKeywords(logMessage)
+	full := len(config.LogMessageBuffer) >= config.MaxBufferSize
+	logBufferMu.Unlock()
+
 	// If the buffer exceeds the max size, send it to Discord
-	if len(config.LogMessageBuffer) >= config.MaxBufferSize {
+	if full {
 		flushLogBufferToDiscord()
 	}
 }
 
 func flushLogBufferToDiscord() {
-	if len(config.LogMessageBuffer) == 0 {
-		return // No messages to send
-	}
 	if !config.IsDiscordEnabled || config.DiscordSession == nil {
 		return
 	}
 
-	const discordMaxMessageLength = 2000
+	logSendMu.Lock()
+	defer logSendMu.Unlock()
+
+	// Take the buffer, lines arriving while it is sent go into the next flush
+	logBufferMu.Lock()
 	message := config.LogMessageBuffer
+	config.LogMessageBuffer = ""
+	logBufferMu.Unlock()
+	if len(message) == 0 {
+		return // No messages to send
+	}
+
+	const discordMaxMessageLength = 2000
 
 	for len(message) > 0 {
 		// Determine how much of the message we can send
@@ -42,7 +59,4 @@ func flushLogBufferToDiscord() {
 		// Move to the next chunk
 		message = message[chunkSize:]
 	}
-
-	// Clear the buffer after sending
-	config.LogMessageBuffer = ""
 }
@@ -0,0 +1,27 @@
+package discord
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+var sendErrors atomic.Uint64
+
+// SendErrors returns the number of Discord API requests that failed since the controller started
+func SendErrors() uint64 {
+	return sendErrors.Load()
+}
+
+// countingTransport counts failed Discord API requests. Rate limited requests are left out,
+// discordgo waits and retries them on its own.
+type countingTransport struct {
+	next http.RoundTripper
+}
+
+func (t countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || (resp.StatusCode >= 400 && resp.StatusCode != http.StatusTooManyRequests) {
+		sendErrors.Add(1)
+	}
+	return resp, err
+}
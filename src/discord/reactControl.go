@@ -2,7 +2,6 @@ package discord
 
 import (
 	"fmt"
-	"time"
 
 	"StationeersServerUI/src/config"
 
@@ -47,8 +46,8 @@ func reactionAddHandler(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
 		return
 	}
 
-	// Check if the reaction was added to the last exception message
-	if r.MessageID == config.ExceptionMessageID {
+	// Check if the reaction was added to an exception message
+	if isExceptionMessage(r.MessageID) {
 		handleExceptionReactions(s, r)
 		return
 	}
@@ -62,10 +61,9 @@ func handleExceptionReactions(s *discordgo.Session, r *discordgo.MessageReaction
 	switch r.Emoji.Name {
 	case "♻️": // Stop server action due to exception
 		actionMessage = "🛑 Server is manually restarting due to critical exception."
-		SendCommandToAPI("/stop")
-		//sleep 5 sec
-		time.Sleep(5 * time.Second)
-		SendCommandToAPI("/start")
+		if message, ok := restartServer(); !ok {
+			actionMessage = message
+		}
 
 	default:
 		fmt.Println("Unknown reaction:", r.Emoji.Name)
@@ -96,17 +94,11 @@ func handleControlReactions(s *discordgo.Session, r *discordgo.MessageReactionAd
 
 	switch r.Emoji.Name {
 	case "▶️": // Start action
-		SendCommandToAPI("/start")
-		actionMessage = "🕛Server is Starting..."
+		actionMessage, _ = startServer()
 	case "⏹️": // Stop action
-		SendCommandToAPI("/stop")
-		actionMessage = "🛑Server is Stopping..."
+		actionMessage, _ = stopServer()
 	case "♻️": // Restart action
-		actionMessage = "♻️Server is restarting..."
-		SendCommandToAPI("/stop")
-		//sleep 5 sec
-		time.Sleep(5 * time.Second)
-		SendCommandToAPI("/start")
+		actionMessage, _ = restartServer()
 
 	default:
 		fmt.Println("Unknown reaction:", r.Emoji.Name)
@@ -122,7 +114,7 @@ func handleControlReactions(s *discordgo.Session, r *discordgo.MessageReactionAd
 	username := user.Username
 
 	// Send the action message to the control channel
-	sendMessageToStatusChannel(fmt.Sprintf("%s triggered by %s.", actionMessage, username))
+	SendMessageToStatusChannel(fmt.Sprintf("%s triggered by %s.", actionMessage, username))
 
 	// Remove the reaction after processing
 	err = s.MessageReactionRemove(config.ControlPanelChannelID, r.MessageID, r.Emoji.APIName(), r.UserID)
@@ -8,6 +8,30 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
+// SendMessageToChannel sends a message to a channel by its role (status, saves, error, control, log) or its ID
+func SendMessageToChannel(channel, message string) {
+	switch channel {
+	case "status":
+		SendMessageToStatusChannel(message)
+	case "saves":
+		SendMessageToSavesChannel(message)
+	case "error":
+		sendMessageToErrorChannel(message)
+	case "control":
+		SendMessageToControlChannel(message)
+	case "log":
+		AddToLogBuffer(message)
+	default:
+		if config.DiscordSession == nil {
+			fmt.Println("Discord session is not initialized")
+			return
+		}
+		if _, err := config.DiscordSession.ChannelMessageSend(channel, message); err != nil {
+			fmt.Printf("Error sending message to channel %s: %v\n", channel, err)
+		}
+	}
+}
+
 func SendMessageToControlChannel(message string) {
 	if config.DiscordSession == nil {
 		fmt.Println("Discord session is not initialized")
@@ -22,7 +46,7 @@ func SendMessageToControlChannel(message string) {
 	}
 }
 
-func sendMessageToStatusChannel(message string) {
+func SendMessageToStatusChannel(message string) {
 	if config.DiscordSession == nil {
 		fmt.Println("Discord session is not initialized")
 		return
@@ -134,7 +158,9 @@ func sendAndEditMessageInConnectedPlayersChannel(channelID, message string) {
 
 // BOT STATUS
 func updateBotStatus(s *discordgo.Session) {
+	config.ConnectedPlayersMu.Lock()
 	playerCount := len(config.ConnectedPlayers)
+	config.ConnectedPlayersMu.Unlock()
 	statusMessage := fmt.Sprintf("%d Employees connected", playerCount)
 	err := s.UpdateGameStatus(0, statusMessage)
 	if err != nil {
@@ -0,0 +1,68 @@
+package discord
+
+import (
+	"StationeersServerUI/src/service"
+	"errors"
+	"fmt"
+)
+
+// Server controls the dedicated server in-process, it is set before the bot starts
+var Server service.Server
+
+// startServer starts the server and returns the message to show in Discord
+func startServer() (string, bool) {
+	err := Server.Start()
+	if errors.Is(err, service.ErrServerRunning) {
+		return "⚠️Server is already running.", false
+	}
+	if err != nil {
+		fmt.Println("Error starting server:", err)
+		return fmt.Sprintf("❌Failed to start the server: %v", err), false
+	}
+	return "🕛Server is starting...", true
+}
+
+// stopServer stops the server and returns the message to show in Discord
+func stopServer() (string, bool) {
+	result, err := Server.Stop()
+	if errors.Is(err, service.ErrServerNotRunning) {
+		if result.RestartCancelled {
+			return "✅Server is not running. Pending automatic restart cancelled.", true
+		}
+		return "⚠️Server is not running.", false
+	}
+	if err != nil {
+		fmt.Println("Error stopping server:", err)
+		return fmt.Sprintf("❌Failed to stop the server: %v", err), false
+	}
+	return "✅Server stopped.", true
+}
+
+// restartServer stops the server if it runs and starts it again, it returns the message to show in Discord
+func restartServer() (string, bool) {
+	if _, err := Server.Restart(); err != nil {
+		fmt.Println("Error restarting server:", err)
+		return fmt.Sprintf("❌Failed to restart the server: %v", err), false
+	}
+	return "♻️Server stopped and is starting again...", true
+}
+
+// restoreBackup stops the server and restores the backup, the caller starts the server again
+func restoreBackup(index int) error {
+	if _, err := Server.Stop(); err != nil && !errors.Is(err, service.ErrServerNotRunning) {
+		return fmt.Errorf("could not stop the server: %w", err)
+	}
+	return Server.RestoreBackup(index)
+}
+
+// describeRestoreError explains why a restore failed
+func describeRestoreError(index int, err error) string {
+	switch {
+	case errors.Is(err, service.ErrBackupNotFound):
+		return fmt.Sprintf("❌There is no backup with index %d. Use `!list` to see the available backups.", index)
+	case errors.Is(err, service.ErrCannotRestore):
+		return fmt.Sprintf("❌Cannot restore backup %d: %v", index, err)
+	default:
+		return fmt.Sprintf("❌Failed to restore backup at index %d: %v", index, err)
+	}
+}
@@ -0,0 +1,125 @@
+// Package events turns the output of the dedicated server into typed events and fans them out to subscribers.
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Type string
+
+const (
+	ServerLoading      Type = "ServerLoading" // the server made a step towards availability
+	ServerReady        Type = "ServerReady"
+	PlayerConnecting   Type = "PlayerConnecting"
+	PlayerReady        Type = "PlayerReady"
+	PlayerDisconnected Type = "PlayerDisconnected"
+	WorldSaved         Type = "WorldSaved"
+	Exception          Type = "Exception"
+	ProcessExited      Type = "ProcessExited"
+)
+
+// Types lists every event type, for validating subscriptions from config files
+var Types = []Type{ServerLoading, ServerReady, PlayerConnecting, PlayerReady, PlayerDisconnected, WorldSaved, Exception, ProcessExited}
+
+// Event is something that happened to the dedicated server, only the fields of its type are set
+type Event struct {
+	Type        Type      `json:"type"`
+	Time        time.Time `json:"time"`
+	Player      string    `json:"player,omitempty"`      // player events
+	SteamID     string    `json:"steamId,omitempty"`     // player events
+	BackupIndex int       `json:"backupIndex,omitempty"` // WorldSaved
+	Message     string    `json:"message,omitempty"`     // the stack trace for Exception, the exit reason for ProcessExited
+	Signature   string    `json:"signature,omitempty"`   // Exception: identifies repeats of the same exception
+	Count       int       `json:"count,omitempty"`       // Exception: occurrences of the signature in the current window
+	ExitCode    int       `json:"exitCode,omitempty"`    // ProcessExited
+	Crashed     bool      `json:"crashed,omitempty"`     // ProcessExited
+}
+
+// recentSize is how many events are kept for clients that connect later
+const recentSize = 100
+
+// Subscription receives events through its own buffered queue, a subscriber that falls behind loses events
+type Subscription struct {
+	name    string
+	events  chan Event
+	dropped uint64 // guarded by mu
+}
+
+// Events returns the queue of the subscription, it is closed by Unsubscribe
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+var (
+	mu          sync.Mutex
+	subscribers = make(map[*Subscription]struct{})
+	recent      []Event
+)
+
+// Subscribe registers a subscriber with a queue of the given size, the name shows up when it drops events
+func Subscribe(name string, queueSize int) *Subscription {
+	mu.Lock()
+	defer mu.Unlock()
+
+	sub := &Subscription{name: name, events: make(chan Event, queueSize)}
+	subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its queue
+func Unsubscribe(sub *Subscription) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := subscribers[sub]; !ok {
+		return
+	}
+	delete(subscribers, sub)
+	close(sub.events)
+}
+
+// Publish hands an event to every subscriber without blocking, so a slow subscriber cannot stall the server output
+func Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	recent = append(recent, e)
+	if len(recent) > recentSize {
+		recent = recent[len(recent)-recentSize:]
+	}
+
+	for sub := range subscribers {
+		select {
+		case sub.events <- e:
+		default:
+			sub.dropped++
+			fmt.Printf("Event subscriber %s is falling behind, dropped %s event (%d dropped so far)\n", sub.name, e.Type, sub.dropped)
+		}
+	}
+}
+
+// Recent returns the last published events, oldest first
+func Recent() []Event {
+	mu.Lock()
+	defer mu.Unlock()
+
+	events := make([]Event, len(recent))
+	copy(events, recent)
+	return events
+}
+
+// ValidType reports whether t names an event type
+func ValidType(t Type) bool {
+	for _, known := range Types {
+		if known == t {
+			return true
+		}
+	}
+	return false
+}
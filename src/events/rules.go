@@ -0,0 +1,300 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// RulesFilePath is where the log pattern rules are read from, it is created with the default rules if missing
+const RulesFilePath = "./UIMod/rules.json"
+
+// Action types of a rule
+const (
+	ActionEvent   = "event"   // publish an event of the given type
+	ActionNotify  = "notify"  // send a message to a Discord channel
+	ActionRestart = "restart" // restart the server
+)
+
+// Action is what happens when a rule matches a log line
+type Action struct {
+	Type    string `json:"type"`
+	Event   Type   `json:"event,omitempty"`   // event: the event type, named groups "player", "steamId", "backupIndex" and "message" fill its fields
+	Channel string `json:"channel,omitempty"` // notify: status, saves, error, control, log or a Discord channel ID
+	Message string `json:"message,omitempty"` // notify: {field} is replaced by a named group, {line} by the log line
+}
+
+// Rule matches server log lines with a regular expression, named groups are captured as fields
+type Rule struct {
+	Name     string   `json:"name"`
+	Pattern  string   `json:"pattern"`
+	Severity string   `json:"severity"` // info, warning or error
+	Actions  []Action `json:"actions"`
+
+	re *regexp.Regexp
+}
+
+// Match is a rule that matched a log line, notify messages are already filled in
+type Match struct {
+	Rule     string            `json:"rule"`
+	Severity string            `json:"severity"`
+	Fields   map[string]string `json:"fields"`
+	Actions  []Action          `json:"actions"`
+	Events   []Event           `json:"events,omitempty"`
+}
+
+var (
+	rulesMu       sync.RWMutex
+	rules         []Rule
+	rulesLoadErr  error     // error of the last load, the previous rules stay active
+	rulesLoadedAt time.Time // when the active rules were loaded
+)
+
+// defaultRules describe the log lines of the dedicated server the controller knows about
+var defaultRules = []Rule{
+	{Name: "server-ready", Pattern: `Ready`, Severity: "info", Actions: []Action{{Type: ActionEvent, Event: ServerReady}}},
+	{Name: "server-loading", Pattern: `Unloading 1 Unused Serialized files`, Severity: "info", Actions: []Action{{Type: ActionEvent, Event: ServerLoading}}},
+	{
+		// Example: "Client Jacksonthemaster (76561198334231312) is ready!"
+		Name: "player-ready", Pattern: `Client\s+(?P<player>.+)\s+\((?P<steamId>\d+)\)\s+is\s+ready!`, Severity: "info",
+		Actions: []Action{{Type: ActionEvent, Event: PlayerReady}},
+	},
+	{
+		// Example: "Client Jacksonthemaster (76561198334231312). Receiving"
+		Name: "player-connecting", Pattern: `Client:?\s+(?P<player>.+?)\s+\((?P<steamId>\d+)\)\.\s+Receiving`, Severity: "info",
+		Actions: []Action{{Type: ActionEvent, Event: PlayerConnecting}},
+	},
+	{
+		// Example: "Client disconnected: 135108291984612402 | Jacksonthemaster connectTime: 1234,5s, ClientId: 76561198334231312"
+		Name: "player-disconnected", Pattern: `Client\s+disconnected:\s+\d+\s+\|\s+(?P<player>.+)\s+connectTime:\s+\d+,\d+s,\s+ClientId:\s+(?P<steamId>\d+)`, Severity: "info",
+		Actions: []Action{{Type: ActionEvent, Event: PlayerDisconnected}},
+	},
+	{
+		// Example: "World Saved: C:/SteamCMD/Stationeers/saves/EuropaProd, BackupIndex: 1057"
+		Name: "world-saved", Pattern: `World Saved:\s.*,\sBackupIndex:\s(?P<backupIndex>\d+)`, Severity: "info",
+		Actions: []Action{{Type: ActionEvent, Event: WorldSaved}},
+	},
+	{
+		// Messy exceptions, based on common patterns in the stack trace
+		Name: "exception", Pattern: `^\s*>\s*\d{2}:\d{2}:\d{2}:.*Exception.*|>\s+\d{2}:\d{2}:\d{2}:.*StackTrace|EXCEPTION`, Severity: "error",
+		Actions: []Action{{Type: ActionEvent, Event: Exception}},
+	},
+}
+
+// validateRules compiles the patterns and checks the actions of every rule
+func validateRules(list []Rule) error {
+	names := make(map[string]bool)
+	for i := range list {
+		rule := &list[i]
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rule name %q is used twice", rule.Name)
+		}
+		names[rule.Name] = true
+
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("rule %s: invalid pattern: %v", rule.Name, err)
+		}
+		rule.re = re
+
+		switch rule.Severity {
+		case "":
+			rule.Severity = "info"
+		case "info", "warning", "error":
+		default:
+			return fmt.Errorf("rule %s: severity must be info, warning or error", rule.Name)
+		}
+
+		if len(rule.Actions) == 0 {
+			return fmt.Errorf("rule %s has no actions", rule.Name)
+		}
+		for _, action := range rule.Actions {
+			switch action.Type {
+			case ActionEvent:
+				if !ValidType(action.Event) {
+					return fmt.Errorf("rule %s: unknown event type %q", rule.Name, action.Event)
+				}
+			case ActionNotify:
+				if action.Channel == "" || action.Message == "" {
+					return fmt.Errorf("rule %s: notify actions need a channel and a message", rule.Name)
+				}
+			case ActionRestart:
+			default:
+				return fmt.Errorf("rule %s: unknown action %q, use event, notify or restart", rule.Name, action.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadRules reads the rules file, writing the default rules first if it does not exist.
+// An invalid file is reported and the rules loaded before stay active.
+func LoadRules() error {
+	data, err := os.ReadFile(RulesFilePath)
+	if os.IsNotExist(err) {
+		data, err = json.MarshalIndent(defaultRules, "", "  ")
+		if err == nil {
+			err = os.WriteFile(RulesFilePath, data, 0644)
+		}
+		if err != nil {
+			fmt.Println("Error writing default rules file:", err)
+		}
+		err = nil
+	}
+
+	var list []Rule
+	if err == nil {
+		err = json.Unmarshal(data, &list)
+	}
+	if err == nil {
+		err = validateRules(list)
+	}
+
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+
+	if err != nil {
+		rulesLoadErr = fmt.Errorf("error loading %s: %v", RulesFilePath, err)
+		if rules == nil {
+			// Nothing loaded yet, fall back to the built-in rules
+			list = append([]Rule(nil), defaultRules...)
+			validateRules(list)
+			rules = list
+			rulesLoadedAt = time.Now()
+		}
+		return rulesLoadErr
+	}
+
+	rules = list
+	rulesLoadErr = nil
+	rulesLoadedAt = time.Now()
+	fmt.Printf("Loaded %d log rules from %s\n", len(list), RulesFilePath)
+	return nil
+}
+
+// WatchRules reloads the rules whenever the rules file changes
+func WatchRules() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Println("Error creating rules watcher:", err)
+		return
+	}
+	defer watcher.Close()
+
+	// Watch the directory, editors often replace the file instead of writing to it
+	if err := watcher.Add(filepath.Dir(RulesFilePath)); err != nil {
+		fmt.Println("Error watching rules file:", err)
+		return
+	}
+
+	var reload *time.Timer
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Base(event.Name) != filepath.Base(RulesFilePath) || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			// Editors write in several steps, reload once they are done
+			if reload != nil {
+				reload.Stop()
+			}
+			reload = time.AfterFunc(200*time.Millisecond, func() {
+				if err := LoadRules(); err != nil {
+					fmt.Println("⚠️", err, "- keeping the previous rules")
+				}
+			})
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Println("Error watching rules file:", err)
+		}
+	}
+}
+
+// Rules returns the active rules, when they were loaded and the error of the last load
+func Rules() ([]Rule, time.Time, error) {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	list := make([]Rule, len(rules))
+	copy(list, rules)
+	return list, rulesLoadedAt, rulesLoadErr
+}
+
+// MatchLine runs a log line against all rules and returns the ones that matched, in rule order
+func MatchLine(line string) []Match {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	var matches []Match
+	for _, rule := range rules {
+		groups := rule.re.FindStringSubmatch(line)
+		if groups == nil {
+			continue
+		}
+
+		fields := make(map[string]string)
+		for i, name := range rule.re.SubexpNames() {
+			if name != "" && i < len(groups) {
+				fields[name] = groups[i]
+			}
+		}
+
+		match := Match{Rule: rule.Name, Severity: rule.Severity, Fields: fields}
+		for _, action := range rule.Actions {
+			if action.Type == ActionNotify {
+				action.Message = fillTemplate(action.Message, fields, line)
+			}
+			if action.Type == ActionEvent {
+				match.Events = append(match.Events, eventFromFields(action.Event, fields, line))
+			}
+			match.Actions = append(match.Actions, action)
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// Parse returns the events a line of server output stands for, usually none
+func Parse(line string) []Event {
+	var list []Event
+	for _, match := range MatchLine(line) {
+		list = append(list, match.Events...)
+	}
+	return list
+}
+
+func eventFromFields(t Type, fields map[string]string, line string) Event {
+	e := Event{Type: t, Time: time.Now(), Player: fields["player"], SteamID: fields["steamId"], Message: fields["message"]}
+	if index, err := strconv.Atoi(fields["backupIndex"]); err == nil {
+		e.BackupIndex = index
+	}
+	if e.Type == Exception && e.Message == "" {
+		e.Message = line
+	}
+	return e
+}
+
+func fillTemplate(message string, fields map[string]string, line string) string {
+	replacements := []string{"{line}", line}
+	for name, value := range fields {
+		replacements = append(replacements, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(replacements...).Replace(message)
+}
@@ -55,6 +55,11 @@ func CheckAndDownloadUIMod() {
 			"config.json":        "https://raw.githubusercontent.com/JacksonTheMaster/StationeersServerUI/main/UIMod/config.json",
 			"config.xml":         "https://raw.githubusercontent.com/JacksonTheMaster/StationeersServerUI/main/UIMod/config.xml",
 			"index.html":         "https://raw.githubusercontent.com/JacksonTheMaster/StationeersServerUI/main/UIMod/index.html",
+			"login.html":         "https://raw.githubusercontent.com/JacksonTheMaster/StationeersServerUI/main/UIMod/login.html",
+			"setup.html":         "https://raw.githubusercontent.com/JacksonTheMaster/StationeersServerUI/main/UIMod/setup.html",
+			"tokens.html":        "https://raw.githubusercontent.com/JacksonTheMaster/StationeersServerUI/main/UIMod/tokens.html",
+			"schedules.html":     "https://raw.githubusercontent.com/JacksonTheMaster/StationeersServerUI/main/UIMod/schedules.html",
+			"users.html":         "https://raw.githubusercontent.com/JacksonTheMaster/StationeersServerUI/main/UIMod/users.html",
 			"script.js":          "https://raw.githubusercontent.com/JacksonTheMaster/StationeersServerUI/main/UIMod/script.js",
 			"stationeers.png":    "https://raw.githubusercontent.com/JacksonTheMaster/StationeersServerUI/main/UIMod/stationeers.png",
 			"style.css":          "https://raw.githubusercontent.com/JacksonTheMaster/StationeersServerUI/main/UIMod/style.css",
@@ -0,0 +1,97 @@
+// Package lifecycle tracks which phase of its lifecycle the dedicated server is in.
+package lifecycle
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type State string
+
+const (
+	Stopped   State = "Stopped"
+	Starting  State = "Starting"
+	Ready     State = "Ready"
+	Stopping  State = "Stopping"
+	Crashed   State = "Crashed"
+	Updating  State = "Updating"
+	Restoring State = "Restoring"
+)
+
+// States lists every state
+var States = []State{Stopped, Starting, Ready, Stopping, Crashed, Updating, Restoring}
+
+// allowedTransitions lists the states reachable from each state
+var allowedTransitions = map[State][]State{
+	Stopped:   {Starting, Updating, Restoring},
+	Starting:  {Ready, Stopping, Stopped, Crashed},
+	Ready:     {Stopping, Crashed},
+	Stopping:  {Stopped},
+	Crashed:   {Starting, Stopped, Updating, Restoring},
+	Updating:  {Stopped},
+	Restoring: {Stopped},
+}
+
+// TransitionError is returned when the server cannot switch to a state from the state it is in
+type TransitionError struct {
+	From State
+	To   State
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("server is %s, cannot switch to %s", e.From, e.To)
+}
+
+var (
+	mu      sync.Mutex
+	current = Stopped
+	since   = time.Now()
+)
+
+// Current returns the current state and when it was entered
+func Current() (State, time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+	return current, since
+}
+
+// Is reports whether the server is currently in the given state
+func Is(state State) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return current == state
+}
+
+// Transition moves to the given state if that is allowed from the current state
+func Transition(to State) error {
+	mu.Lock()
+	defer mu.Unlock()
+	return transitionLocked(to)
+}
+
+// TransitionFrom moves to the given state only if the server is currently in the expected state
+func TransitionFrom(from, to State) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if current != from {
+		return false
+	}
+	return transitionLocked(to) == nil
+}
+
+func transitionLocked(to State) error {
+	if current == to {
+		return nil
+	}
+	for _, allowed := range allowedTransitions[current] {
+		if allowed == to {
+			fmt.Printf("Server state: %s -> %s\n", current, to)
+			current = to
+			since = time.Now()
+			return nil
+		}
+	}
+	return &TransitionError{From: current, To: to}
+}
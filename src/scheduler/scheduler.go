@@ -0,0 +1,402 @@
+// Package scheduler runs server tasks like restarts and backups on cron schedules.
+package scheduler
+
+import (
+	"StationeersServerUI/src/service"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	schedulesFilePath = "./UIMod/schedules.json"
+	maxHistory        = 200
+	previewRuns       = 5
+)
+
+// Action is what a job does when it fires
+type Action string
+
+const (
+	ActionRestart  Action = "restart" // argument "countdown" warns the players first, the restart follows after the longest warning
+	ActionStop     Action = "stop"
+	ActionStart    Action = "start"
+	ActionBackup   Action = "backup"   // save the world, which writes a backup
+	ActionUpdate   Action = "update"   // update with SteamCMD, argument "validate" also validates the files
+	ActionConsole  Action = "console"  // argument is the console command
+	ActionAnnounce Action = "announce" // argument is sent to the players with say
+)
+
+// ErrNotFound is returned for an unknown schedule id
+var ErrNotFound = errors.New("schedule not found")
+
+// Job is a scheduled task
+type Job struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Cron       string    `json:"cron"`
+	Action     Action    `json:"action"`
+	Argument   string    `json:"argument,omitempty"`
+	Enabled    bool      `json:"enabled"`
+	SkipNext   bool      `json:"skipNext"`
+	LastRun    time.Time `json:"lastRun,omitempty"`
+	LastResult string    `json:"lastResult,omitempty"`
+	schedule   *Cron
+}
+
+// JobInfo is a job together with the times it fires next
+type JobInfo struct {
+	Job
+	NextRuns []time.Time `json:"nextRuns"`
+}
+
+// Run is one entry in the run history
+type Run struct {
+	JobID   string    `json:"jobId"`
+	Name    string    `json:"name"`
+	Action  Action    `json:"action"`
+	Time    time.Time `json:"time"`
+	Skipped bool      `json:"skipped,omitempty"`
+	Success bool      `json:"success"`
+	Result  string    `json:"result"`
+}
+
+type schedulesFile struct {
+	Schedules []*Job `json:"schedules"`
+	History   []Run  `json:"history"`
+}
+
+var (
+	mu      sync.Mutex
+	jobs    []*Job
+	history []Run // newest last
+	server  service.Server
+	stop    = make(chan struct{})
+)
+
+// Start loads the schedules from disk and runs them against the server
+func Start(s service.Server) {
+	server = s
+	if err := load(); err != nil {
+		fmt.Println("⚠️Error loading schedules:", err)
+	}
+	go run()
+}
+
+// load reads the schedules file, a missing file means no schedules exist
+func load() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	data, err := os.ReadFile(schedulesFilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var file schedulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("error parsing %s: %v", schedulesFilePath, err)
+	}
+	for _, job := range file.Schedules {
+		schedule, err := ParseCron(job.Cron)
+		if err != nil {
+			// Keep the job so it shows up in the UI, but do not run it
+			fmt.Printf("⚠️Schedule %s is disabled: %v\n", job.Name, err)
+			job.Enabled = false
+		}
+		job.schedule = schedule
+	}
+	jobs = file.Schedules
+	history = file.History
+	return nil
+}
+
+func saveLocked() error {
+	data, err := json.MarshalIndent(schedulesFile{Schedules: jobs, History: history}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(schedulesFilePath, data, 0600)
+}
+
+// Stop ends the schedule checks, jobs that are already running finish on their own
+func Stop() {
+	close(stop)
+}
+
+// run checks the schedules at the start of every minute until Stop is called
+func run() {
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		select {
+		case <-time.After(next.Sub(now)):
+		case <-stop:
+			return
+		}
+		runDue(next)
+	}
+}
+
+// runDue starts every enabled job that fires in the minute of t
+func runDue(t time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, job := range jobs {
+		if !job.Enabled || job.schedule == nil || !job.schedule.Matches(t) {
+			continue
+		}
+		if job.SkipNext {
+			job.SkipNext = false
+			fmt.Printf("⏭️Skipping scheduled %s (%s) once\n", job.Name, job.Action)
+			recordLocked(job, Run{Time: t, Skipped: true, Success: true, Result: "Skipped"})
+			continue
+		}
+		go execute(*job, t)
+	}
+	if err := saveLocked(); err != nil {
+		fmt.Println("Error saving schedules file:", err)
+	}
+}
+
+// execute performs the job's action and records the outcome
+func execute(job Job, t time.Time) {
+	fmt.Printf("⏰Running scheduled %s: %s %s\n", job.Name, job.Action, job.Argument)
+	result, err := perform(job)
+	entry := Run{Time: t, Success: err == nil, Result: result}
+	if err != nil {
+		entry.Result = err.Error()
+		fmt.Printf("❌Scheduled %s failed: %v\n", job.Name, err)
+	} else {
+		fmt.Printf("✅Scheduled %s: %s\n", job.Name, result)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, j := range jobs {
+		if j.ID == job.ID {
+			recordLocked(j, entry)
+		}
+	}
+	if err := saveLocked(); err != nil {
+		fmt.Println("Error saving schedules file:", err)
+	}
+}
+
+func perform(job Job) (string, error) {
+	switch job.Action {
+	case ActionRestart:
+		if job.Argument == "countdown" {
+			restartAt, err := server.RestartWithCountdown("scheduled "+job.Name, "scheduler")
+			if err != nil {
+				return "", err
+			}
+			return "Players warned, restart at " + restartAt.Format("15:04:05") + ".", nil
+		}
+		if _, err := server.Restart(); err != nil {
+			return "", err
+		}
+		return "Server restarted.", nil
+	case ActionStop:
+		if _, err := server.Stop(); err != nil {
+			return "", err
+		}
+		return "Server stopped.", nil
+	case ActionStart:
+		if err := server.Start(); err != nil {
+			return "", err
+		}
+		return "Server started.", nil
+	case ActionBackup:
+		if err := server.SaveWorld(); err != nil {
+			return "", err
+		}
+		return "World saved.", nil
+	case ActionUpdate:
+		if err := server.Update(job.Argument == "validate"); err != nil {
+			return "", err
+		}
+		return "Server files updated.", nil
+	case ActionConsole:
+		if err := server.SendConsoleCommand("scheduler", job.Name, job.Argument); err != nil {
+			return "", err
+		}
+		return "Sent " + job.Argument, nil
+	case ActionAnnounce:
+		if err := server.SendConsoleCommand("scheduler", job.Name, "say "+job.Argument); err != nil {
+			return "", err
+		}
+		return "Announced " + job.Argument, nil
+	}
+	return "", fmt.Errorf("unknown action %s", job.Action)
+}
+
+func recordLocked(job *Job, run Run) {
+	run.JobID = job.ID
+	run.Name = job.Name
+	run.Action = job.Action
+	job.LastRun = run.Time
+	job.LastResult = run.Result
+
+	history = append(history, run)
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+}
+
+// validate checks a job sent by a client and parses its cron expression
+func validate(job *Job) error {
+	job.Name = strings.TrimSpace(job.Name)
+	job.Argument = strings.TrimSpace(job.Argument)
+	if job.Name == "" {
+		return errors.New("name is required")
+	}
+
+	schedule, err := ParseCron(job.Cron)
+	if err != nil {
+		return err
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return fmt.Errorf("cron expression %q never fires", job.Cron)
+	}
+	job.schedule = schedule
+
+	switch job.Action {
+	case ActionRestart:
+		if job.Argument != "" && job.Argument != "countdown" {
+			return errors.New(`the restart argument must be empty or "countdown"`)
+		}
+	case ActionStop, ActionStart, ActionBackup:
+	case ActionUpdate:
+		if job.Argument != "" && job.Argument != "validate" {
+			return errors.New(`the update argument must be empty or "validate"`)
+		}
+	case ActionConsole, ActionAnnounce:
+		if job.Argument == "" {
+			return fmt.Errorf("the %s action needs an argument", job.Action)
+		}
+		if err := service.CheckConsoleCommand(job.Argument); err != nil {
+			return fmt.Errorf("the %s argument: %w", job.Action, err)
+		}
+		// Both go through the console allowlist when they fire, an announcement is a say command
+		command := job.Argument
+		if job.Action == ActionAnnounce {
+			command = "say " + command
+		}
+		if !service.ConsoleCommandAllowed(command) {
+			return fmt.Errorf("the %s argument: %w", job.Action, service.ErrCommandNotAllowed)
+		}
+	default:
+		return errors.New("action must be restart, stop, start, backup, update, console or announce")
+	}
+	return nil
+}
+
+func info(job *Job, now time.Time) JobInfo {
+	jobInfo := JobInfo{Job: *job, NextRuns: []time.Time{}}
+	if job.Enabled && job.schedule != nil {
+		jobInfo.NextRuns = job.schedule.NextRuns(now, previewRuns)
+	}
+	return jobInfo
+}
+
+// List returns all jobs with their next runs
+func List() []JobInfo {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	list := []JobInfo{}
+	for _, job := range jobs {
+		list = append(list, info(job, now))
+	}
+	return list
+}
+
+// Create validates and adds a job
+func Create(job Job) (JobInfo, error) {
+	if err := validate(&job); err != nil {
+		return JobInfo{}, err
+	}
+	id := make([]byte, 4)
+	rand.Read(id)
+	job.ID = hex.EncodeToString(id)
+	job.SkipNext = false
+	job.LastRun = time.Time{}
+	job.LastResult = ""
+
+	mu.Lock()
+	defer mu.Unlock()
+	jobs = append(jobs, &job)
+	return info(&job, time.Now()), saveLocked()
+}
+
+// Update replaces the settings of a job, its run state is kept
+func Update(id string, changed Job) (JobInfo, error) {
+	if err := validate(&changed); err != nil {
+		return JobInfo{}, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, job := range jobs {
+		if job.ID == id {
+			job.Name = changed.Name
+			job.Cron = changed.Cron
+			job.Action = changed.Action
+			job.Argument = changed.Argument
+			job.Enabled = changed.Enabled
+			job.schedule = changed.schedule
+			return info(job, time.Now()), saveLocked()
+		}
+	}
+	return JobInfo{}, ErrNotFound
+}
+
+// Delete removes a job, its history is kept
+func Delete(id string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	for i, job := range jobs {
+		if job.ID == id {
+			jobs = append(jobs[:i], jobs[i+1:]...)
+			return saveLocked()
+		}
+	}
+	return ErrNotFound
+}
+
+// SetSkipNext makes a job skip its next occurrence, or undoes that
+func SetSkipNext(id string, skip bool) (JobInfo, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, job := range jobs {
+		if job.ID == id {
+			job.SkipNext = skip
+			return info(job, time.Now()), saveLocked()
+		}
+	}
+	return JobInfo{}, ErrNotFound
+}
+
+// History returns the recorded runs, newest first
+func History() []Run {
+	mu.Lock()
+	defer mu.Unlock()
+
+	list := make([]Run, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		list = append(list, history[i])
+	}
+	return list
+}
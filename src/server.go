@@ -2,18 +2,23 @@ package main
 
 import (
 	"StationeersServerUI/src/api"
+	"StationeersServerUI/src/auth"
 	"StationeersServerUI/src/config"
 	discord "StationeersServerUI/src/discord"
+	"StationeersServerUI/src/events"
 	"StationeersServerUI/src/install"
+	"StationeersServerUI/src/scheduler"
+	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 
 	_ "net/http/pprof"
-
-	"github.com/r3labs/sse"
 )
 
 const (
@@ -27,6 +32,12 @@ const (
 	colorCyan    = "\033[36m"
 )
 
+// shutdownTimeout is how long open requests get to finish when the controller shuts down
+const shutdownTimeout = 10 * time.Second
+
+// stopLogForwarding ends the forwarding of server output to the Discord log channel
+var stopLogForwarding = func() {}
+
 func main() {
 	var wg sync.WaitGroup
 
@@ -47,13 +58,33 @@ func main() {
 	fmt.Println(string(colorBlue), "Loading configuration from", configFilePath, string(colorReset))
 	config.LoadConfig(configFilePath)
 
+	if err := auth.Init(); err != nil {
+		fmt.Printf(string(colorRed)+"Error loading user accounts: %v\n"+string(colorReset), err)
+		os.Exit(1)
+	}
+
+	if err := events.LoadRules(); err != nil {
+		fmt.Println(string(colorYellow), "⚠️", err, "- using the built-in log rules", string(colorReset))
+	}
+	go events.WatchRules()
+
 	// If Discord is enabled, start the Discord bot
 	if config.IsDiscordEnabled {
 		fmt.Println(string(colorGreen), "Starting Discord bot...", string(colorReset))
+		discord.Server = api.NewService()
 		go discord.StartDiscordBot()
 	}
 
-	go startLogStream()
+	if config.IsDiscordEnabled {
+		stopLogForwarding = api.SubscribeOutput(discord.AddToLogBuffer)
+	}
+	api.StartWebhooks()
+	api.StartExceptionPolicies()
+	api.StartIdleWatch()
+	api.StartResourceMonitor()
+	scheduler.Start(api.NewService())
+	// After the watchers above, so they see the adopted server become ready
+	api.ReattachServer()
 
 	fmt.Println(string(colorBlue), "Starting API services...", string(colorReset))
 	go api.StartAPI()
@@ -61,17 +92,94 @@ func main() {
 	go api.WatchBackupDir()
 
 	fs := http.FileServer(http.Dir("./UIMod"))
-	http.Handle("/static/", http.StripPrefix("/static/", fs))
-	http.HandleFunc("/", api.ServeUI)
-	http.HandleFunc("/start", api.StartServer)
-	http.HandleFunc("/stop", api.StopServer)
-	http.HandleFunc("/output", api.GetOutput)
-	http.HandleFunc("/backups", api.ListBackups)
-	http.HandleFunc("/restore", api.RestoreBackup)
-	http.HandleFunc("/config", api.HandleConfig)
-	http.HandleFunc("/saveconfig", api.SaveConfig)
-	http.HandleFunc("/furtherconfig", api.HandleConfigJSON)
-	http.HandleFunc("/saveconfigasjson", api.SaveConfigJSON)
+	http.Handle("/static/", http.StripPrefix("/static/", api.ServeStatic(fs)))
+	http.HandleFunc("/", auth.Require(auth.PermViewServer, api.ServeUI))
+	http.HandleFunc("/start", auth.Require(auth.PermControlServer, api.StartServer))
+	http.HandleFunc("/stop", auth.Require(auth.PermControlServer, api.StopServer))
+	http.HandleFunc("/output", auth.Require(auth.PermViewServer, api.GetOutput))
+	http.HandleFunc("/backups", auth.Require(auth.PermViewServer, api.ListBackups))
+	http.HandleFunc("/restore", auth.Require(auth.PermRestoreBackups, api.RestoreBackup))
+	http.HandleFunc("/config", auth.Require(auth.PermEditConfig, api.HandleConfig))
+	http.HandleFunc("/saveconfig", auth.Require(auth.PermEditConfig, api.SaveConfig))
+	http.HandleFunc("/furtherconfig", auth.Require(auth.PermEditConfig, api.HandleConfigJSON))
+	http.HandleFunc("/saveconfigasjson", auth.Require(auth.PermEditConfig, api.SaveConfigJSON))
+	http.HandleFunc("/api/status", auth.Require(auth.PermViewServer, api.GetStatus))
+	http.HandleFunc("POST /api/wake", auth.Require(auth.PermWakeServer, api.WakeServer))
+	http.HandleFunc("POST /api/restart/countdown", auth.Require(auth.PermControlServer, api.StartCountdownRestart))
+	http.HandleFunc("DELETE /api/restart/countdown", auth.Require(auth.PermControlServer, api.CancelCountdownRestart))
+	http.HandleFunc("GET /api/resources", auth.Require(auth.PermViewServer, api.GetResources))
+	http.HandleFunc("GET /api/resources/stream", auth.Require(auth.PermViewServer, api.StreamResources))
+	http.HandleFunc("GET /metrics", auth.Require(auth.PermViewServer, api.Metrics))
+	http.HandleFunc("/api/console", auth.Require(auth.PermControlServer, api.HandleConsole))
+	http.HandleFunc("/api/console/audit", auth.Require(auth.PermViewServer, api.GetConsoleAudit))
+	http.HandleFunc("/api/logs", auth.Require(auth.PermViewServer, api.ListLogs))
+	http.HandleFunc("/api/logs/download", auth.Require(auth.PermViewServer, api.DownloadLog))
+	http.HandleFunc("/api/logs/search", auth.Require(auth.PermViewServer, api.SearchLogs))
+	http.HandleFunc("/api/events", auth.Require(auth.PermViewServer, api.GetEvents))
+	http.HandleFunc("/api/exceptions", auth.Require(auth.PermViewServer, api.GetExceptions))
+	http.HandleFunc("/api/policies", auth.Require(auth.PermViewServer, api.GetExceptionPolicies))
+	http.HandleFunc("/api/rules", auth.Require(auth.PermViewServer, api.GetRules))
+	http.HandleFunc("/api/rules/test", auth.Require(auth.PermViewServer, api.TestRules))
+	http.HandleFunc("/api/bans", auth.Require(auth.PermViewServer, api.ListBans))
+	http.HandleFunc("/api/ban", auth.Require(auth.PermManagePlayers, api.BanPlayer))
+	http.HandleFunc("/api/unban", auth.Require(auth.PermManagePlayers, api.UnbanPlayer))
+	http.HandleFunc("/schedules", auth.Require(auth.PermViewServer, api.ServeSchedulesPage))
+	http.HandleFunc("GET /api/schedules", auth.Require(auth.PermViewServer, api.GetSchedules))
+	http.HandleFunc("POST /api/schedules", auth.Require(auth.PermManageSchedule, api.CreateSchedule))
+	http.HandleFunc("PUT /api/schedules/{id}", auth.Require(auth.PermManageSchedule, api.UpdateSchedule))
+	http.HandleFunc("DELETE /api/schedules/{id}", auth.Require(auth.PermManageSchedule, api.DeleteSchedule))
+	http.HandleFunc("POST /api/schedules/{id}/skip", auth.Require(auth.PermManageSchedule, api.SkipSchedule))
+	http.HandleFunc("GET /api/schedules/history", auth.Require(auth.PermViewServer, api.GetScheduleHistory))
+	http.HandleFunc("GET /api/schedules/preview", auth.Require(auth.PermViewServer, api.PreviewSchedule))
+
+	// Versioned JSON API, the routes above stay for the bundled UI
+	http.HandleFunc("/api/v1/", api.V1NotFound)
+	http.HandleFunc("GET /api/v1/openapi.json", api.ServeOpenAPI)
+	http.HandleFunc("GET /api/v1/status", auth.Require(auth.PermViewServer, api.V1GetStatus))
+	http.HandleFunc("POST /api/v1/server/start", auth.Require(auth.PermControlServer, api.V1StartServer))
+	http.HandleFunc("POST /api/v1/server/stop", auth.Require(auth.PermControlServer, api.V1StopServer))
+	http.HandleFunc("POST /api/v1/server/restart", auth.Require(auth.PermControlServer, api.V1RestartServer))
+	http.HandleFunc("POST /api/v1/server/wake", auth.Require(auth.PermWakeServer, api.WakeServer))
+	http.HandleFunc("POST /api/v1/server/restart/countdown", auth.Require(auth.PermControlServer, api.StartCountdownRestart))
+	http.HandleFunc("DELETE /api/v1/server/restart/countdown", auth.Require(auth.PermControlServer, api.CancelCountdownRestart))
+	http.HandleFunc("POST /api/v1/console", auth.Require(auth.PermControlServer, api.V1SendConsoleCommand))
+	http.HandleFunc("GET /api/v1/console/audit", auth.Require(auth.PermViewServer, api.V1GetConsoleAudit))
+	http.HandleFunc("GET /api/v1/backups", auth.Require(auth.PermViewServer, api.V1ListBackups))
+	http.HandleFunc("POST /api/v1/backups/{index}/restore", auth.Require(auth.PermRestoreBackups, api.V1RestoreBackup))
+	http.HandleFunc("GET /api/v1/config", auth.Require(auth.PermEditConfig, api.V1GetConfig))
+	http.HandleFunc("PATCH /api/v1/config/game", auth.Require(auth.PermEditConfig, api.V1PatchGameConfig))
+	http.HandleFunc("PATCH /api/v1/config/controller", auth.Require(auth.PermEditConfig, api.V1PatchControllerConfig))
+	http.HandleFunc("GET /api/v1/resources", auth.Require(auth.PermViewServer, api.GetResources))
+	http.HandleFunc("GET /api/v1/resources/stream", auth.Require(auth.PermViewServer, api.StreamResources))
+	http.HandleFunc("GET /api/v1/events", auth.Require(auth.PermViewServer, api.GetEvents))
+	http.HandleFunc("GET /api/v1/exceptions", auth.Require(auth.PermViewServer, api.GetExceptions))
+	http.HandleFunc("GET /api/v1/policies", auth.Require(auth.PermViewServer, api.GetExceptionPolicies))
+	http.HandleFunc("GET /api/v1/rules", auth.Require(auth.PermViewServer, api.GetRules))
+	http.HandleFunc("POST /api/v1/rules/test", auth.Require(auth.PermViewServer, api.TestRules))
+	http.HandleFunc("GET /api/v1/players", auth.Require(auth.PermViewServer, api.V1ListPlayers))
+	http.HandleFunc("GET /api/v1/players/bans", auth.Require(auth.PermViewServer, api.V1ListBans))
+	http.HandleFunc("PUT /api/v1/players/bans/{steamId}", auth.Require(auth.PermManagePlayers, api.V1BanPlayer))
+	http.HandleFunc("DELETE /api/v1/players/bans/{steamId}", auth.Require(auth.PermManagePlayers, api.V1UnbanPlayer))
+	http.HandleFunc("GET /api/v1/logs", auth.Require(auth.PermViewServer, api.V1ListLogs))
+	http.HandleFunc("GET /api/v1/logs/search", auth.Require(auth.PermViewServer, api.V1SearchLogs))
+	http.HandleFunc("GET /api/v1/logs/{name}", auth.Require(auth.PermViewServer, api.V1DownloadLog))
+	http.HandleFunc("GET /api/v1/schedules", auth.Require(auth.PermViewServer, api.GetSchedules))
+	http.HandleFunc("POST /api/v1/schedules", auth.Require(auth.PermManageSchedule, api.CreateSchedule))
+	http.HandleFunc("PUT /api/v1/schedules/{id}", auth.Require(auth.PermManageSchedule, api.UpdateSchedule))
+	http.HandleFunc("DELETE /api/v1/schedules/{id}", auth.Require(auth.PermManageSchedule, api.DeleteSchedule))
+	http.HandleFunc("POST /api/v1/schedules/{id}/skip", auth.Require(auth.PermManageSchedule, api.SkipSchedule))
+	http.HandleFunc("GET /api/v1/schedules/history", auth.Require(auth.PermViewServer, api.GetScheduleHistory))
+	http.HandleFunc("GET /api/v1/schedules/preview", auth.Require(auth.PermViewServer, api.PreviewSchedule))
+
+	http.HandleFunc("/login", auth.HandleLogin)
+	http.HandleFunc("/logout", auth.HandleLogout)
+	http.HandleFunc("/setup", auth.HandleSetup)
+	http.HandleFunc("/users", auth.ServeUsersPage)
+	http.HandleFunc("/api/users", auth.Require(auth.PermManageUsers, auth.HandleUsers))
+	http.HandleFunc("/api/users/password", auth.HandlePassword)
+	http.HandleFunc("/api/me", auth.HandleMe)
+	http.HandleFunc("/tokens", auth.Require(auth.PermManageTokens, auth.ServeTokensPage))
+	http.HandleFunc("/api/tokens", auth.Require(auth.PermManageTokens, auth.HandleTokens))
 
 	fmt.Println(string(colorYellow), "Starting the HTTP server on port 8080...", string(colorReset))
 	fmt.Println(string(colorGreen), "UI available at: http://0.0.0.0:8080", string(colorReset))
@@ -83,50 +191,61 @@ func main() {
 	if config.Branch != "Release" {
 		fmt.Println(string(colorRed), "⚠️Starting pprof server on /debug/pprof", string(colorReset))
 	}
-	// Start the HTTP server and check for errors
-	err := http.ListenAndServe("0.0.0.0:8080", nil)
+	// Stop gracefully on Ctrl+C and on SIGTERM from service managers and container runtimes
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	// Request contexts end on shutdown, so the output and event streams let go of their clients
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	srv := &http.Server{
+		Addr:        "0.0.0.0:8080",
+		Handler:     auth.Middleware(http.DefaultServeMux),
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+	srv.RegisterOnShutdown(cancelRequests)
 
-	if err != nil {
+	// Start the HTTP server and check for errors, every route except the login pages requires authentication
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
 		fmt.Printf(string(colorRed)+"Error starting HTTP server: %v\n"+string(colorReset), err)
 		os.Exit(1)
+	case <-ctx.Done():
 	}
 
+	// A second signal exits right away
+	stopSignals()
+	os.Exit(shutdown(srv))
 }
 
-func startLogStream() {
-	client := sse.NewClient("http://localhost:8080/output")
-	client.Headers["Content-Type"] = "text/event-stream"
-	client.Headers["Connection"] = "keep-alive"
-	client.Headers["Cache-Control"] = "no-cache"
+// shutdown stops the controller: no new requests, then the game server and background routines, then Discord.
+// It returns the exit code, 1 if the game server could not be stopped cleanly.
+func shutdown(srv *http.Server) int {
+	fmt.Println(string(colorYellow), "Shutting down, press Ctrl+C again to exit immediately...", string(colorReset))
+	code := 0
 
-	retryDelay := 5 * time.Second // Retry every 5 seconds
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		fmt.Printf(string(colorRed)+"Error stopping the HTTP server: %v\n"+string(colorReset), err)
+	}
 
-	go func() {
-		for {
-			fmt.Println(string(colorYellow), "Attempting to connect to SSE stream...", string(colorReset))
-
-			err := client.SubscribeRaw(func(msg *sse.Event) {
-				if len(msg.Data) > 0 {
-					logMessage := string(msg.Data)
-					if config.IsDiscordEnabled {
-						discord.AddToLogBuffer(logMessage)
-					}
-
-					//fmt.Println(string(colorGreen), "Serverlog:", logMessage, string(colorReset))
-					//dont spam the console with the server log
-				}
-			})
-
-			if err != nil {
-				// Instead of logging errors repeatedly, retry silently until the endpoint is available
-				fmt.Println(string(colorYellow), "SSE stream not available yet, retrying in 5 seconds...", string(colorReset))
-				time.Sleep(retryDelay)
-				continue
-			}
-
-			// Successfully connected, break the loop and handle messages
-			fmt.Println(string(colorGreen), "Connected to SSE stream.", string(colorReset))
-			return
-		}
-	}()
+	scheduler.Stop()
+	if err := api.Shutdown(); err != nil {
+		fmt.Printf(string(colorRed)+"Error stopping the server: %v\n"+string(colorReset), err)
+		code = 1
+	}
+
+	if config.IsDiscordEnabled {
+		// Unsubscribe first, so no output is added to the log buffer while it is flushed
+		stopLogForwarding()
+		discord.StopDiscordBot()
+	}
+
+	fmt.Println(string(colorGreen), "Shutdown complete.", string(colorReset))
+	return code
 }
@@ -0,0 +1,89 @@
+// Package service defines the server control operations shared between the HTTP API and the Discord bot.
+package service
+
+import (
+	"StationeersServerUI/src/config"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Errors returned by Server, callers compare with errors.Is
+var (
+	ErrServerRunning     = errors.New("Server is already running.")
+	ErrServerNotRunning  = errors.New("Server is not running.")
+	ErrBackupNotFound    = errors.New("backup not found")
+	ErrCannotRestore     = errors.New("cannot restore backup")
+	ErrCommandNotAllowed = errors.New("command is not on the console allowlist")
+	ErrCommandInvalid    = errors.New("command must be a single line without control characters")
+	ErrCountdownActive   = errors.New("a restart countdown is already running")
+	ErrNoCountdown       = errors.New("no restart countdown is running")
+	ErrCountdownFinal    = errors.New("the final warning has been sent, the restart can no longer be cancelled")
+	ErrNotSleeping       = errors.New("the server was not stopped for being idle, ask an operator to start it")
+)
+
+// CheckConsoleCommand rejects commands with line breaks or other control characters, the console would
+// run everything after a line break as a further command that never passed the allowlist
+func CheckConsoleCommand(command string) error {
+	if strings.IndexFunc(command, unicode.IsControl) >= 0 {
+		return ErrCommandInvalid
+	}
+	return nil
+}
+
+// ConsoleCommandAllowed matches the first word of the command against the configured allowlist, "*" allows everything
+func ConsoleCommandAllowed(command string) bool {
+	words := strings.Fields(command)
+	if len(words) == 0 {
+		return false
+	}
+	verb := strings.ToLower(words[0])
+	for _, allowed := range config.ConsoleAllowedCommands {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == "*" || allowed == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// BackupInfo is one restorable backup in the Safebackups folder
+type BackupInfo struct {
+	Index   int       `json:"index"`
+	Created time.Time `json:"created"`
+}
+
+// StopResult describes how a stop went
+type StopResult struct {
+	Steps            []string // shutdown steps in the order they happened
+	RestartCancelled bool     // the server was not running but an automatic restart was pending and has been cancelled
+}
+
+// Server controls the dedicated server, it is implemented by the api package
+type Server interface {
+	// Start launches the server, it returns once the process runs, not when it is ready
+	Start() error
+	// Stop saves and stops the server and blocks until the process has exited
+	Stop() (StopResult, error)
+	// Restart stops the server if it is running and starts it again
+	Restart() (StopResult, error)
+	// RestartWithCountdown warns the players in game and restarts the server when the countdown ends, it returns the restart time
+	RestartWithCountdown(reason, requestedBy string) (time.Time, error)
+	// CancelRestart cancels a countdown restart until the final warning
+	CancelRestart(requestedBy string) error
+	// Wake starts the server again after it was stopped for having no players
+	Wake(requestedBy string) error
+	// ListBackups returns the restorable backups, newest first
+	ListBackups() ([]BackupInfo, error)
+	// RestoreBackup copies a backup over the current save, the server must be stopped
+	RestoreBackup(index int) error
+	// SaveWorld makes the running server save and waits until the world is saved, which also writes a backup
+	SaveWorld() error
+	// Update updates the server files with SteamCMD, validate also repairs changed files. The server must be stopped.
+	Update(validate bool) error
+	// SendConsoleCommand sends an allowlisted command to the server console, source and user are audited
+	SendConsoleCommand(source, user, command string) error
+	// SetBanned adds or removes a SteamID from the blacklist and reports whether anything changed
+	SetBanned(steamID string, banned bool) (bool, error)
+}